@@ -0,0 +1,36 @@
+package minicommerce
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckoutService turns a cart into an order that has a payment attached to it
+type CheckoutService interface {
+	Checkout(ctx context.Context, orderID string) (*Order, error)
+}
+
+// OrderPlacer places the order of a checkout before the payment processor is asked for the money.
+// Place attaches the pending payment to the order, saves both and redeems the coupon of the order at once.
+// It returns an AlreadyCheckedOutError when the order already has a payment and a CouponExhaustedError when
+// the coupon has been redeemed MaxRedemptions times. Cancel takes the payment off the order, deletes it
+// and gives the redemption back again, so the cart can be checked out once more when the payment fails
+type OrderPlacer interface {
+	Place(ctx context.Context, order *Order, payment *Payment) error
+	Cancel(ctx context.Context, order *Order, payment *Payment) error
+}
+
+// OrderEditor saves the changes to an order that hasn't been checked out yet. The order is read again when it is
+// saved, so it returns an AlreadyCheckedOutError when a payment was attached to it in the meantime
+type OrderEditor interface {
+	Edit(ctx context.Context, order *Order) error
+}
+
+// AlreadyCheckedOutError is returned when an order already has a payment attached to it
+type AlreadyCheckedOutError struct {
+	OrderID string
+}
+
+func (e *AlreadyCheckedOutError) Error() string {
+	return fmt.Sprintf("The order: %s has already been checked out", e.OrderID)
+}
@@ -2,26 +2,207 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/downloads"
+	"github.com/eikc/minicommerce/pkg/encryption"
+	"github.com/eikc/minicommerce/pkg/gc"
+	"github.com/eikc/minicommerce/pkg/http"
 	"github.com/eikc/minicommerce/pkg/storage"
 )
 
+// the download limits used when they are not configured, 5 downloads within 30 days of the first one
+const (
+	defaultMaxDownloads   = 5
+	defaultDownloadWindow = 30 * 24 * 60 * 60
+)
+
+// defaultGracePeriod is how old an orphaned file must be before the gc command deletes it
+const defaultGracePeriod = 24 * time.Hour
+
 func main() {
 	ctx := context.Background()
 	bucketURL := os.Getenv("bucketURL")
 	projectID := os.Getenv("projectID")
+	webhookSecret := os.Getenv("webhookSecret")
+	downloadSecret := os.Getenv("downloadSecret")
+	keyringFile := KeyringFile(os.Getenv("keyringFile"))
+
+	// rotating the key only touches the keyring, so it works without a bucket
+	if len(os.Args) > 1 && os.Args[1] == "rotate-key" {
+		rotateKey(keyringFile)
+		return
+	}
+
+	switch {
+	case bucketURL == "":
+		log.Fatal("the bucketURL environment variable must be set, to gs://bucket, file:///path/to/dir or mem://")
+	case strings.HasPrefix(bucketURL, "mem://"):
+		log.Print("Files are stored in memory and will be lost when the server stops")
+	}
+
+	if len(os.Args) > 1 {
+		runCommand(ctx, os.Args[1:], storage.BucketURL(bucketURL), keyringFile, projectID)
+		return
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	srv, err := NewServer(ctx, storage.BucketURL(bucketURL), projectID)
+	downloadLimits := minicommerce.DownloadLimits{
+		MaxDownloads: envInt("maxDownloads", defaultMaxDownloads),
+		Window:       envInt("downloadWindow", defaultDownloadWindow),
+	}
+
+	fakePayments := FakePayments(envBool("fakePayments"))
+
+	// the load balancers in front of the server, without them X-Forwarded-For is not trusted
+	trustedProxies, err := http.ParseTrustedProxies(os.Getenv("trustedProxies"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	srv, cleanup, err := NewServer(ctx, storage.BucketURL(bucketURL), keyringFile, projectID, http.WebhookSecret(webhookSecret), downloads.SigningSecret(downloadSecret), downloadLimits, fakePayments, trustedProxies)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
 	log.Printf("Listening on port %s", port)
-	log.Fatal(srv.Run(port))
+	err = srv.Run(interrupted(), port)
+
+	// the server has stopped serving requests, so nothing uses the storage anymore
+	cleanup()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// interrupted returns a context that is done when the process is asked to stop
+func interrupted() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Print("Shutting down")
+		cancel()
+	}()
+
+	return ctx
+}
+
+// runCommand runs one of the maintenance commands instead of the server, args starts with the name of the command
+func runCommand(ctx context.Context, args []string, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string) {
+	switch args[0] {
+	case "migrate-locations":
+		migration, cleanup, err := NewLocationsMigration(ctx, bucketURL, keyringFile, projectID)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		report, err := migration.Run(ctx)
+		cleanup()
+		if report != nil {
+			log.Printf("Migrated %d downloadables, %d were already stored in the downloadables folder", report.Migrated, report.Unchanged)
+			for _, location := range report.Shared {
+				log.Printf("More than one downloadable was stored at %s, they now all have the last uploaded file", location)
+			}
+		}
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	case "gc":
+		collectGarbage(ctx, args, bucketURL, keyringFile, projectID)
+	default:
+		log.Fatalf("unknown command %s, the commands are migrate-locations, gc and rotate-key", args[0])
+	}
+}
+
+// collectGarbage reports the orphaned and missing files and deletes the orphans older than the grace period
+func collectGarbage(ctx context.Context, args []string, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string) {
+	flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only report the orphaned files, without deleting them")
+	grace := flags.Duration("grace", defaultGracePeriod, "keep orphaned files younger than this, they can belong to an upload in progress")
+	flags.Parse(args[1:])
+
+	collector, cleanup, err := NewCollector(ctx, bucketURL, keyringFile, projectID)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	report, err := collector.Run(ctx, gc.Options{GracePeriod: int64(grace.Seconds()), DryRun: *dryRun})
+	cleanup()
+	if report != nil {
+		for _, downloadable := range report.Missing {
+			log.Printf("The file %s of the downloadable %s is missing", downloadable.Location, downloadable.ID)
+		}
+		for _, obj := range report.Orphaned {
+			log.Printf("The file %s of %d stored bytes belongs to no downloadable", obj.Location, obj.Size)
+		}
+
+		action := "Deleted"
+		if *dryRun {
+			action = "Would delete"
+		}
+		log.Printf("%s %d of %d orphaned files, %d downloadables are missing their file", action, len(report.Expired), len(report.Orphaned), len(report.Missing))
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// rotateKey adds a new key to the keyring and makes it the one new files are encrypted with,
+// the files that are already stored keep being read with the key they were written with
+func rotateKey(keyringFile KeyringFile) {
+	if keyringFile == "" {
+		log.Fatal("the keyringFile environment variable must be set to rotate the key")
+	}
+
+	id, err := encryption.RotateKeyring(string(keyringFile))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("New files are encrypted with key %d, restart the servers to start using it", id)
+}
+
+// envInt reads an integer from the environment variable, an empty variable gives the fallback
+func envInt(name string, fallback int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Fatalf("the environment variable %s must be a number: %s", name, err.Error())
+	}
+
+	return i
+}
+
+// envBool reads a boolean from the environment variable, an empty variable is false
+func envBool(name string) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("the environment variable %s must be true or false: %s", name, err.Error())
+	}
+
+	return b
 }
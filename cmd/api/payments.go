@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/fakepayment"
+)
+
+// FakePayments tells the server to use the in-process fake payment provider, which approves every payment
+type FakePayments bool
+
+// errNoPaymentProvider is returned when the server is started without a payment provider
+var errNoPaymentProvider = errors.New("no payment processor is integrated, set fakePayments=true to run with the fake provider that approves every payment")
+
+// newPaymentProvider returns the provider payments are processed with. No payment processor is integrated yet,
+// so the server refuses to start unless the fake provider is asked for explicitly
+func newPaymentProvider(fake FakePayments) (minicommerce.PaymentProvider, error) {
+	if !fake {
+		return nil, errNoPaymentProvider
+	}
+
+	log.Print("Payments are processed by the fake provider, every payment is approved and nothing is charged")
+	return fakepayment.NewProvider(), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/encryption"
+	"github.com/eikc/minicommerce/pkg/storage"
+)
+
+// KeyringFile is the path of the keyring the files in the storage are encrypted with,
+// without a keyring the files are stored as they are
+type KeyringFile string
+
+// fileStorage is the storage the files are kept in, with or without the encryption.
+// It is a struct so wire can bind the storage interfaces to it
+type fileStorage struct {
+	minicommerce.Storage
+}
+
+// newStorage opens the bucket and wraps it in the encryption when there is a keyring
+func newStorage(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile) (*fileStorage, func(), error) {
+	s, cleanup, err := storage.NewStorage(ctx, bucketURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if keyringFile == "" {
+		return &fileStorage{s}, cleanup, nil
+	}
+
+	keyring, err := encryption.LoadKeyring(string(keyringFile))
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return &fileStorage{encryption.NewStorage(s, keyring)}, cleanup, nil
+}
@@ -11,23 +11,106 @@ import (
 
 	f "cloud.google.com/go/firestore"
 
+	"github.com/eikc/minicommerce/pkg/checkout"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/downloads"
 	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/gc"
+	"github.com/eikc/minicommerce/pkg/migrate"
+	"github.com/eikc/minicommerce/pkg/pricing"
 	"github.com/eikc/minicommerce/pkg/storage"
+	"github.com/eikc/minicommerce/pkg/time"
+	"github.com/eikc/minicommerce/pkg/uuid"
 	"github.com/google/wire"
 
 	"github.com/eikc/minicommerce/pkg/http"
 )
 
 // NewServer is using wire to construct the correct server struct
-func NewServer(ctx context.Context, bucketURL storage.BucketURL, projectID string, opts ...option.ClientOption) (*http.Server, error) {
+func NewServer(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string, webhookSecret http.WebhookSecret, downloadSecret downloads.SigningSecret, downloadLimits minicommerce.DownloadLimits, fakePayments FakePayments, trustedProxies http.TrustedProxies, opts ...option.ClientOption) (*http.Server, func(), error) {
 
 	wire.Build(
 		http.NewServer,
 		f.NewClient,
-		storage.NewStorage,
+		newStorage,
 		firestore.NewDownloadableService,
-		wire.Bind(new(minicommerce.Storage), new(storage.Storage)),
-		wire.Bind(new(minicommerce.DownloadableRepository), new(firestore.DownloadableService)))
+		firestore.NewProductRepository,
+		firestore.NewOrdersRepository,
+		firestore.NewPaymentsRepository,
+		firestore.NewPaymentEventsRepository,
+		firestore.NewCouponsRepository,
+		firestore.NewDownloadsRepository,
+		checkout.NewService,
+		coupons.NewService,
+		coupons.NewGenerator,
+		downloads.NewService,
+		downloads.NewSigner,
+		pricing.DefaultRules,
+		newPaymentProvider,
+		time.NewService,
+		uuid.NewGenerator,
+		wire.Bind(new(minicommerce.Storage), new(fileStorage)),
+		wire.Bind(new(minicommerce.StorageStater), new(fileStorage)),
+		wire.Bind(new(minicommerce.StorageRangeReader), new(fileStorage)),
+		wire.Bind(new(minicommerce.DownloadableRepository), new(firestore.DownloadableService)),
+		wire.Bind(new(minicommerce.DownloadableReader), new(firestore.DownloadableService)),
+		wire.Bind(new(minicommerce.DownloadableReferenceChecker), new(firestore.ProductRepository)),
+		wire.Bind(new(minicommerce.ProductRepository), new(firestore.ProductRepository)),
+		wire.Bind(new(minicommerce.ProductReader), new(firestore.ProductRepository)),
+		wire.Bind(new(minicommerce.OrderRepository), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.OrderReader), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.OrderPlacer), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.OrderEditor), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.OrderRefunder), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.CustomerOrderReader), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.ProductReferenceChecker), new(firestore.OrdersRepository)),
+		wire.Bind(new(minicommerce.PaymentRepository), new(firestore.PaymentsRepository)),
+		wire.Bind(new(minicommerce.PaymentReader), new(firestore.PaymentsRepository)),
+		wire.Bind(new(minicommerce.PaymentEventRepository), new(firestore.PaymentEventsRepository)),
+		wire.Bind(new(minicommerce.CouponRepository), new(firestore.CouponsRepository)),
+		wire.Bind(new(minicommerce.CouponReader), new(firestore.CouponsRepository)),
+		wire.Bind(new(minicommerce.CouponBatchWriter), new(firestore.CouponsRepository)),
+		wire.Bind(new(minicommerce.CouponGenerator), new(coupons.Generator)),
+		wire.Bind(new(minicommerce.CouponValidator), new(coupons.Service)),
+		wire.Bind(new(minicommerce.CheckoutService), new(checkout.Service)),
+		wire.Bind(new(minicommerce.DownloadService), new(downloads.Service)),
+		wire.Bind(new(minicommerce.DownloadCounter), new(firestore.DownloadsRepository)),
+		wire.Bind(new(minicommerce.DownloadAccessLogger), new(firestore.DownloadsRepository)),
+		wire.Bind(new(minicommerce.DownloadSigner), new(downloads.Signer)),
+		wire.Bind(new(minicommerce.TimeService), new(time.Service)),
+		wire.Bind(new(minicommerce.IDGenerator), new(uuid.Generator)))
 
-	return &http.Server{}, nil
+	return &http.Server{}, nil, nil
+}
+
+// NewLocationsMigration is using wire to construct the migration that moves downloadables to their storage keys
+func NewLocationsMigration(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string, opts ...option.ClientOption) (*migrate.Locations, func(), error) {
+
+	wire.Build(
+		migrate.NewLocations,
+		f.NewClient,
+		newStorage,
+		firestore.NewDownloadableService,
+		wire.Bind(new(minicommerce.DownloadableReader), new(firestore.DownloadableService)),
+		wire.Bind(new(minicommerce.DownloadableUpdater), new(firestore.DownloadableService)),
+		wire.Bind(new(minicommerce.StorageCopier), new(fileStorage)))
+
+	return &migrate.Locations{}, nil, nil
+}
+
+// NewCollector is using wire to construct the collector of orphaned files
+func NewCollector(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string, opts ...option.ClientOption) (*gc.Collector, func(), error) {
+
+	wire.Build(
+		gc.NewCollector,
+		f.NewClient,
+		newStorage,
+		firestore.NewDownloadableService,
+		time.NewService,
+		wire.Bind(new(minicommerce.DownloadableReader), new(firestore.DownloadableService)),
+		wire.Bind(new(minicommerce.StorageLister), new(fileStorage)),
+		wire.Bind(new(minicommerce.StorageDeleter), new(fileStorage)),
+		wire.Bind(new(minicommerce.TimeService), new(time.Service)))
+
+	return &gc.Collector{}, nil, nil
 }
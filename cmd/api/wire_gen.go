@@ -8,21 +8,86 @@ package main
 import (
 	"cloud.google.com/go/firestore"
 	"context"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/checkout"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/downloads"
 	firestore2 "github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/gc"
 	"github.com/eikc/minicommerce/pkg/http"
+	"github.com/eikc/minicommerce/pkg/migrate"
+	"github.com/eikc/minicommerce/pkg/pricing"
 	"github.com/eikc/minicommerce/pkg/storage"
+	"github.com/eikc/minicommerce/pkg/time"
+	"github.com/eikc/minicommerce/pkg/uuid"
 	"google.golang.org/api/option"
 )
 
 // Injectors from wire.go:
 
-func NewServer(ctx context.Context, bucketURL storage.BucketURL, projectID string, opts ...option.ClientOption) (*http.Server, error) {
+func NewServer(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string, webhookSecret http.WebhookSecret, downloadSecret downloads.SigningSecret, downloadLimits minicommerce.DownloadLimits, fakePayments FakePayments, trustedProxies http.TrustedProxies, opts ...option.ClientOption) (*http.Server, func(), error) {
 	client, err := firestore.NewClient(ctx, projectID, opts...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	downloadableService := firestore2.NewDownloadableService(client)
-	storageStorage := storage.NewStorage(bucketURL)
-	server := http.NewServer(downloadableService, storageStorage)
-	return server, nil
+	productRepository := firestore2.NewProductRepository(client)
+	ordersRepository := firestore2.NewOrdersRepository(client)
+	paymentsRepository := firestore2.NewPaymentsRepository(client)
+	paymentProvider, err := newPaymentProvider(fakePayments)
+	if err != nil {
+		return nil, nil, err
+	}
+	couponsRepository := firestore2.NewCouponsRepository(client)
+	service := time.NewService()
+	couponsService := coupons.NewService(couponsRepository, ordersRepository, service)
+	generator := uuid.NewGenerator()
+	rules := pricing.DefaultRules()
+	checkoutService := checkout.NewService(ordersRepository, ordersRepository, productRepository, paymentsRepository, paymentProvider, couponsService, generator, rules)
+	paymentEventsRepository := firestore2.NewPaymentEventsRepository(client)
+	couponsGenerator := coupons.NewGenerator(couponsRepository, generator)
+	mainFileStorage, cleanup, err := newStorage(ctx, bucketURL, keyringFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	downloadsRepository := firestore2.NewDownloadsRepository(client)
+	downloadsService := downloads.NewService(ordersRepository, paymentsRepository, downloadableService, mainFileStorage, mainFileStorage, downloadsRepository, downloadsRepository, service, downloadLimits)
+	signer := downloads.NewSigner(downloadSecret)
+	server := http.NewServer(downloadableService, productRepository, productRepository, ordersRepository, ordersRepository, ordersRepository, checkoutService, paymentsRepository, paymentEventsRepository, ordersRepository, couponsRepository, couponsGenerator, couponsService, downloadsService, signer, mainFileStorage, service, generator, webhookSecret, trustedProxies, rules)
+	return server, func() {
+		cleanup()
+	}, nil
+}
+
+func NewLocationsMigration(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string, opts ...option.ClientOption) (*migrate.Locations, func(), error) {
+	client, err := firestore.NewClient(ctx, projectID, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	downloadableService := firestore2.NewDownloadableService(client)
+	mainFileStorage, cleanup, err := newStorage(ctx, bucketURL, keyringFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	locations := migrate.NewLocations(downloadableService, downloadableService, mainFileStorage)
+	return locations, func() {
+		cleanup()
+	}, nil
+}
+
+func NewCollector(ctx context.Context, bucketURL storage.BucketURL, keyringFile KeyringFile, projectID string, opts ...option.ClientOption) (*gc.Collector, func(), error) {
+	client, err := firestore.NewClient(ctx, projectID, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	downloadableService := firestore2.NewDownloadableService(client)
+	mainFileStorage, cleanup, err := newStorage(ctx, bucketURL, keyringFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	service := time.NewService()
+	collector := gc.NewCollector(downloadableService, mainFileStorage, mainFileStorage, service)
+	return collector, func() {
+		cleanup()
+	}, nil
 }
@@ -1,13 +1,88 @@
 package minicommerce
 
-// Coupon is the domain and data model representing a Coupon in miniCommerce
+import (
+	"context"
+	"fmt"
+)
+
+// Coupon is the domain and data model representing a Coupon in miniCommerce.
+// RedeemBy is the last unix time the coupon can be redeemed at and RedeemBefore is the unix time
+// from which it can no longer be redeemed, zero means that there is no such limit.
+// Redemptions is the number of times the coupon has been redeemed, it can be redeemed
+// MaxRedemptions times where zero means unlimited.
+// The discount only applies to the items of an order matching ProductIDs and ProductTypes when they are set,
+// and the coupon can only be used on orders of at least MinimumAmount and, with FirstOrderOnly, on the first order of a customer.
+// Batch is the ID shared by the coupons that were generated together, it is empty for coupons that are created one by one
 type Coupon struct {
-	ID             string  `firestore:"-"`
-	Description    string  `firestore:"description"`
-	Active         bool    `firestore:"active"`
-	AmountOff      int64   `firestore:"amountOff"`
-	PercentOff     float64 `firestore:"percentOff"`
-	MaxRedemptions int64   `firestore:"maxRedemptions"`
-	RedeemBy       int64   `firestore:"redeemBy"`
-	RedeemBefore   int64   `firestore:"redeemBefore"`
+	ID             string        `firestore:"-" json:"id"`
+	Description    string        `firestore:"description" json:"description"`
+	Active         bool          `firestore:"active" json:"active"`
+	AmountOff      int64         `firestore:"amountOff" json:"amountOff"`
+	PercentOff     float64       `firestore:"percentOff" json:"percentOff"`
+	MaxRedemptions int64         `firestore:"maxRedemptions" json:"maxRedemptions"`
+	Redemptions    int64         `firestore:"redemptions" json:"redemptions"`
+	RedeemBy       int64         `firestore:"redeemBy" json:"redeemBy"`
+	RedeemBefore   int64         `firestore:"redeemBefore" json:"redeemBefore"`
+	ProductIDs     []string      `firestore:"productIds" json:"productIds"`
+	ProductTypes   []ProductType `firestore:"productTypes" json:"productTypes"`
+	MinimumAmount  int64         `firestore:"minimumAmount" json:"minimumAmount"`
+	FirstOrderOnly bool          `firestore:"firstOrderOnly" json:"firstOrderOnly"`
+	Batch          string        `firestore:"batch,omitempty" json:"batch,omitempty"`
+}
+
+// CouponReader is the interface for reading coupons from a given datastore
+type CouponReader interface {
+	GetAll(ctx context.Context) ([]Coupon, error)
+	GetByCode(ctx context.Context, code string) (*Coupon, error)
+	GetByBatch(ctx context.Context, batch string) ([]Coupon, error)
+}
+
+// CouponWriter is the interface for creating a coupon in a given datastore
+type CouponWriter interface {
+	Create(ctx context.Context, coupon Coupon) error
+}
+
+// CouponUpdater is the interface for updating a coupon in a given datastore
+type CouponUpdater interface {
+	Update(ctx context.Context, coupon Coupon) error
+}
+
+// CouponRepository is the interface that combines all readers and writers for a coupon
+type CouponRepository interface {
+	CouponReader
+	CouponWriter
+	CouponUpdater
+}
+
+// CouponBatchWriter is the interface for creating many coupons at once in a given datastore.
+// CreateAll creates either all of the coupons or, as far as the datastore allows, none of them
+type CouponBatchWriter interface {
+	CreateAll(ctx context.Context, coupons []Coupon) error
+}
+
+// CouponGenerator creates coupons with unique random codes from a template coupon,
+// the generated coupons share a Batch so their codes can be read back later
+type CouponGenerator interface {
+	Generate(ctx context.Context, template Coupon, count int) ([]Coupon, error)
+}
+
+// CouponValidator checks whether a coupon can be redeemed on the order
+type CouponValidator interface {
+	Validate(ctx context.Context, code string, order *Order) (*Coupon, error)
+}
+
+// CouponRedeemer counts the redemptions of a coupon, Redeem must never let the
+// redemptions exceed MaxRedemptions even when the coupon is redeemed concurrently
+type CouponRedeemer interface {
+	Redeem(ctx context.Context, code string) error
+	Release(ctx context.Context, code string) error
+}
+
+// CouponExhaustedError is returned when a coupon that has been redeemed MaxRedemptions times is redeemed again
+type CouponExhaustedError struct {
+	Code string
+}
+
+func (e *CouponExhaustedError) Error() string {
+	return fmt.Sprintf("The coupon: %s has been redeemed the maximum number of times", e.Code)
 }
@@ -0,0 +1,98 @@
+package minicommerce
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// DownloadClient is who is downloading a file, it is recorded in the access log
+type DownloadClient struct {
+	IP        string
+	UserAgent string
+}
+
+// DownloadLink is the signed link a downloadable is downloaded through, it stops working at the unix time Expires
+type DownloadLink struct {
+	Signature string
+	Expires   int64
+}
+
+// DownloadService gives the buyer of an order access to the downloadables of the digital products in it.
+// Open reads the file of a downloadable from offset to the end through the signed link, so interrupted downloads
+// can be resumed without counting as another download.
+// Purchased reports whether any order can download the downloadable, or will once its payment is captured.
+// Refuse adds a request that was not served to the access log, with the reason it was refused
+type DownloadService interface {
+	Downloadables(ctx context.Context, orderID string) ([]Downloadable, error)
+	Stat(ctx context.Context, orderID, downloadableID string) (*Downloadable, *StorageAttributes, error)
+	Open(ctx context.Context, orderID, downloadableID string, link DownloadLink, offset int64, client DownloadClient) (io.ReadCloser, error)
+	Purchased(ctx context.Context, downloadableID string) (bool, error)
+	Refuse(ctx context.Context, orderID, downloadableID, reason string, client DownloadClient) error
+}
+
+// DownloadSigner signs links to a downloadable of an order, so a link can be verified without the buyer logging in.
+// The link is valid until the unix time expires
+type DownloadSigner interface {
+	Sign(orderID, downloadableID string, expires int64) string
+	Verify(orderID, downloadableID string, expires int64, signature string) bool
+}
+
+// DownloadLimits restricts how many times a downloadable of an order can be downloaded, and for how many seconds
+// after its first download. Zero means no limit
+type DownloadLimits struct {
+	MaxDownloads int64
+	Window       int64
+}
+
+// DownloadCount is the number of times a downloadable has been downloaded through an order,
+// First is the unix time of the first download
+type DownloadCount struct {
+	OrderID        string `firestore:"orderId" json:"orderId"`
+	DownloadableID string `firestore:"downloadableId" json:"downloadableId"`
+	Downloads      int64  `firestore:"downloads" json:"downloads"`
+	First          int64  `firestore:"first" json:"first"`
+}
+
+// DownloadCounter counts the access to a downloadable of an order through the signed link as a download.
+// A request resuming a download through a link, from the client the link was counted for, is not counted again.
+// A download beyond the limits is not counted and returns a DownloadLimitError, the window is checked for resumed
+// downloads as well
+type DownloadCounter interface {
+	Count(ctx context.Context, link DownloadLink, access *DownloadAccess, limits DownloadLimits) error
+}
+
+// DownloadAccess is an entry in the access log of the downloads, every request for the content of a file is logged.
+// Offset is where in the file the request started, it is above zero when a download is resumed. Refused is why
+// the request was not served, it is empty for a request that was
+type DownloadAccess struct {
+	ID             string `firestore:"-" json:"id"`
+	OrderID        string `firestore:"orderId" json:"orderId"`
+	DownloadableID string `firestore:"downloadableId" json:"downloadableId"`
+	Time           int64  `firestore:"time" json:"time"`
+	Offset         int64  `firestore:"offset" json:"offset"`
+	IP             string `firestore:"ip" json:"ip"`
+	UserAgent      string `firestore:"userAgent" json:"userAgent"`
+	Refused        string `firestore:"refused,omitempty" json:"refused,omitempty"`
+}
+
+// DownloadAccessLogger stores the access log of the downloads in a given datastore
+type DownloadAccessLogger interface {
+	Log(ctx context.Context, access *DownloadAccess) error
+}
+
+// DownloadLimitError is returned when a downloadable of an order has been downloaded the maximum number of times,
+// or when the window for downloading it has passed
+type DownloadLimitError struct {
+	OrderID        string
+	DownloadableID string
+	Expired        bool
+}
+
+func (e *DownloadLimitError) Error() string {
+	if e.Expired {
+		return fmt.Sprintf("The window for downloading: %s of the order: %s has passed", e.DownloadableID, e.OrderID)
+	}
+
+	return fmt.Sprintf("The downloadable: %s of the order: %s has been downloaded the maximum number of times", e.DownloadableID, e.OrderID)
+}
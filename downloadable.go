@@ -2,34 +2,107 @@ package minicommerce
 
 import (
 	"context"
+	"path"
+	"strings"
 )
 
-// Downloadable is the location of a downloadable digital product uploaded somewhere to google cloud storage
+// downloadablePrefix is the folder in the storage all downloadables are uploaded to
+const downloadablePrefix = "downloadables"
+
+// DownloadablesFolder is the prefix of the locations in the downloadables folder. Files outside of it were
+// uploaded to the root of the storage, before the downloadables got a folder of their own
+const DownloadablesFolder = downloadablePrefix + "/"
+
+// Downloadable is the location of a downloadable digital product uploaded somewhere to google cloud storage.
+// Name is the original filename and only used for display, the file is stored at Location.
+// Hash is the hex encoded SHA-256 of the file, downloadables with the same hash share a single file.
+// Downloadables uploaded before the hash was computed have an empty Hash and a zero Size
 type Downloadable struct {
-	ID       string `firestore:"-" json:"id"`
-	Name     string `firestore:"name" json:"name"`
-	Location string `firestore:"location" json:"location"`
+	ID          string `firestore:"-" json:"id"`
+	Name        string `firestore:"name" json:"name"`
+	Location    string `firestore:"location" json:"location"`
+	Hash        string `firestore:"hash" json:"hash"`
+	Size        int64  `firestore:"size" json:"size"`
+	ContentType string `firestore:"contentType" json:"contentType"`
 }
 
 // DownloadableReader ...
 type DownloadableReader interface {
 	Get(ctx context.Context, id string) (*Downloadable, error)
+	GetByHash(ctx context.Context, hash string) (*Downloadable, error)
 	GetAll(ctx context.Context) ([]Downloadable, error)
+	List(ctx context.Context, opts ListOptions) (*DownloadablePage, error)
+	LocationReferenced(ctx context.Context, location string) (bool, error)
 }
 
-// DownloadableWriter ..
+// DownloadableWriter creates downloadables. CreateSharingFile creates the downloadable and points it at the file
+// of a downloadable with the same hash, when there is one, in the same transaction. The Location of the downloadable
+// is changed to the shared file
 type DownloadableWriter interface {
 	Create(ctx context.Context, downloadable *Downloadable) error
+	CreateSharingFile(ctx context.Context, downloadable *Downloadable) error
+}
+
+// DownloadableUpdater ...
+type DownloadableUpdater interface {
+	Update(ctx context.Context, downloadable *Downloadable) error
 }
 
-// DownloadableDeleter ...
+// DownloadableDeleter deletes downloadables. DeleteReleasingFile deletes the downloadable and reports whether it was
+// the last one stored at its location, in the same transaction, so its file can be deleted without another upload
+// starting to share it in between
 type DownloadableDeleter interface {
 	Delete(ctx context.Context, id string) error
+	DeleteReleasingFile(ctx context.Context, id string) (bool, error)
 }
 
 // DownloadableRepository ...
 type DownloadableRepository interface {
 	DownloadableReader
 	DownloadableWriter
+	DownloadableUpdater
 	DownloadableDeleter
 }
+
+// DownloadableReferenceChecker checks whether a downloadable is embedded in a product that would break if it was deleted
+type DownloadableReferenceChecker interface {
+	DownloadableReferenced(ctx context.Context, downloadableID string) (bool, error)
+}
+
+// DownloadableLocation is the storage key of an uploaded file. The key starts with the ID of the downloadable,
+// so two uploads with the same filename never overwrite each other
+func DownloadableLocation(id, filename string) string {
+	return path.Join(downloadablePrefix, id, sanitizeFilename(filename))
+}
+
+// LegacyLocation reports whether the location is at the root of the storage, where files were uploaded
+// before the downloadables got a folder of their own
+func LegacyLocation(location string) bool {
+	return !strings.Contains(location, "/")
+}
+
+// sanitizeFilename keeps the letters, digits, dots, dashes and underscores of the base name of the file
+// and replaces everything else with a dash
+func sanitizeFilename(filename string) string {
+	filename = filename[strings.LastIndexAny(filename, `/\`)+1:]
+
+	var b strings.Builder
+	dash := false
+	for _, r := range filename {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_':
+			b.WriteRune(r)
+			dash = false
+		case !dash:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	name := strings.Trim(b.String(), "-.")
+	if name == "" {
+		return "file"
+	}
+
+	return name
+}
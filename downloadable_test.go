@@ -0,0 +1,41 @@
+package minicommerce
+
+import (
+	"testing"
+)
+
+func TestDownloadableLocation(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		location string
+	}{
+		{
+			desc:     "A plain filename is kept as it is",
+			filename: "ebook.pdf",
+			location: "downloadables/abc/ebook.pdf",
+		},
+		{
+			desc:     "Spaces and other characters are replaced with a single dash",
+			filename: "My  Book (final).pdf",
+			location: "downloadables/abc/My-Book-final-.pdf",
+		},
+		{
+			desc:     "Only the base name of a path is used",
+			filename: `..\..\secret/../ebook.pdf`,
+			location: "downloadables/abc/ebook.pdf",
+		},
+		{
+			desc:     "A filename without any usable characters falls back to file",
+			filename: "../",
+			location: "downloadables/abc/file",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if location := DownloadableLocation("abc", tC.filename); location != tC.location {
+				t.Errorf("DownloadableLocation(%q) = %q, want %q", tC.filename, location, tC.location)
+			}
+		})
+	}
+}
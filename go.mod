@@ -11,4 +11,5 @@ require (
 	github.com/julienschmidt/httprouter v1.2.0
 	gocloud.dev v0.15.0
 	google.golang.org/api v0.7.0
+	google.golang.org/grpc v1.21.1
 )
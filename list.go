@@ -0,0 +1,36 @@
+package minicommerce
+
+// SortOrder is the direction a collection is listed in
+type SortOrder string
+
+// List of the sort orders a collection can be listed in
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// ListOptions selects a single page of a collection. The cursor is the opaque NextCursor of the previous page,
+// an empty cursor starts at the first page
+type ListOptions struct {
+	Limit  int
+	Cursor string
+	Order  SortOrder
+}
+
+// ProductFilter narrows down the products that are listed, the zero value matches every product
+type ProductFilter struct {
+	Active *bool
+	Type   ProductType
+}
+
+// ProductPage is a single page of products, NextCursor is empty on the last page
+type ProductPage struct {
+	Products   []Product
+	NextCursor string
+}
+
+// DownloadablePage is a single page of downloadables, NextCursor is empty on the last page
+type DownloadablePage struct {
+	Downloadables []Downloadable
+	NextCursor    string
+}
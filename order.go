@@ -1,26 +1,58 @@
 package minicommerce
 
+import (
+	"context"
+)
+
 // Order represents the domain model for an order or cart in minicommerce
 type Order struct {
-	ID        string    `firestore:"-"`
-	PaymentID string    `firestore:"paymentId"`
-	Coupon    string    `firestore:"coupon"`
-	Items     []Product `firestore:"items"`
-	Customer  Customer  `firestore:"customer"`
-	Refunded  bool      `firestore:"refunded"`
-	Amount    int64     `firestore:"amount"`
-	Discount  int64     `firestore:"discount"`
-	Shipping  int64     `firestore:"shipping"`
-	NetAmount int64     `firestore:"netAmount"`
-	Taxes     int64     `firestore:"taxes"`
-	Total     int64     `firestore:"total"`
+	ID        string    `firestore:"-" json:"id"`
+	PaymentID string    `firestore:"paymentId" json:"paymentId"`
+	Coupon    string    `firestore:"coupon" json:"coupon"`
+	Items     []Product `firestore:"items" json:"items"`
+	Customer  Customer  `firestore:"customer" json:"customer"`
+	Refunded  bool      `firestore:"refunded" json:"refunded"`
+	Amount    int64     `firestore:"amount" json:"amount"`
+	Discount  int64     `firestore:"discount" json:"discount"`
+	Shipping  int64     `firestore:"shipping" json:"shipping"`
+	NetAmount int64     `firestore:"netAmount" json:"netAmount"`
+	Taxes     int64     `firestore:"taxes" json:"taxes"`
+	Total     int64     `firestore:"total" json:"total"`
 }
 
 // Customer is...
 type Customer struct {
-	Name    string `firestore:"name"`
-	Email   string `firestore:"email"`
-	Address string `firestore:"address"`
-	ZipCode string `firestore:"zipCode"`
-	Phone   string `firestore:"phone"`
+	Name    string `firestore:"name" json:"name"`
+	Email   string `firestore:"email" json:"email"`
+	Address string `firestore:"address" json:"address"`
+	ZipCode string `firestore:"zipCode" json:"zipCode"`
+	Phone   string `firestore:"phone" json:"phone"`
+}
+
+// OrderReader is the interface for reading orders from a given datastore
+type OrderReader interface {
+	GetAll(ctx context.Context) ([]Order, error)
+	Get(ctx context.Context, id string) (*Order, error)
+}
+
+// CustomerOrderReader is the interface for reading the orders of a customer from a given datastore
+type CustomerOrderReader interface {
+	GetByCustomerEmail(ctx context.Context, email string) ([]Order, error)
+}
+
+// OrderWriter is the interface for creating an order in a given datastore
+type OrderWriter interface {
+	Create(ctx context.Context, order *Order) error
+}
+
+// OrderUpdater is the interface for updating an order in a given datastore
+type OrderUpdater interface {
+	Update(ctx context.Context, order *Order) error
+}
+
+// OrderRepository is the interface that combines all readers and writers for an order
+type OrderRepository interface {
+	OrderReader
+	OrderWriter
+	OrderUpdater
 }
@@ -1,10 +1,99 @@
 package minicommerce
 
+import (
+	"context"
+	"fmt"
+)
+
 // Payment represents the domain model for payments within the system
 type Payment struct {
-	ID         string `firestore:"-"`
-	ExternalID string `firestore:"externalID,omitempty"`
-	Amount     int64  `firestore:"amount,omitempty"`
-	Paid       bool   `firestore:"paid,omitempty"`
-	Refunded   bool   `firestore:"refunded,omitempty"`
+	ID             string `firestore:"-" json:"id"`
+	OrderID        string `firestore:"orderId,omitempty" json:"orderId"`
+	ExternalID     string `firestore:"externalID,omitempty" json:"externalId"`
+	Amount         int64  `firestore:"amount,omitempty" json:"amount"`
+	Paid           bool   `firestore:"paid,omitempty" json:"paid"`
+	Refunded       bool   `firestore:"refunded,omitempty" json:"refunded"`
+	RefundedAmount int64  `firestore:"refundedAmount,omitempty" json:"refundedAmount"`
+}
+
+// PaymentReader is the interface for reading payments from a given datastore
+type PaymentReader interface {
+	Get(ctx context.Context, id string) (*Payment, error)
+	GetByExternalID(ctx context.Context, externalID string) (*Payment, error)
+}
+
+// PaymentWriter is the interface for creating a payment in a given datastore
+type PaymentWriter interface {
+	Create(ctx context.Context, payment *Payment) error
+}
+
+// PaymentUpdater is the interface for updating a payment in a given datastore. Update replaces the payment,
+// while UpdateCharge only saves the ExternalID, and Paid once it is true, so the checkout never overwrites
+// what the webhook of the processor has saved in the meantime. Modify reads the payment with the ExternalID,
+// lets modify change it and saves it at once, so concurrent events from the processor never overwrite each other.
+// modify reports whether it changed the payment, and is called again when the payment changed in between
+type PaymentUpdater interface {
+	Update(ctx context.Context, payment *Payment) error
+	UpdateCharge(ctx context.Context, payment *Payment) error
+	Modify(ctx context.Context, externalID string, modify func(payment *Payment) bool) (*Payment, error)
+}
+
+// PaymentRepository is the interface that combines all readers and writers for a payment
+type PaymentRepository interface {
+	PaymentReader
+	PaymentWriter
+	PaymentUpdater
+}
+
+// PaymentProvider is the abstraction for talking to a payment processor.
+// Authorize reserves the amount of the payment and sets the ExternalID of it, Void gives the reserved amount back
+// when the payment is not going to be captured. Capture and Refund updates Paid, Refunded and RefundedAmount
+// with the result from the processor. A payment can be partially refunded, it is only marked as Refunded
+// once the whole amount is refunded
+type PaymentProvider interface {
+	Authorize(ctx context.Context, payment *Payment) error
+	Void(ctx context.Context, payment *Payment) error
+	Capture(ctx context.Context, payment *Payment) error
+	Refund(ctx context.Context, payment *Payment, amount int64) error
+	Lookup(ctx context.Context, externalID string) (*Payment, error)
+}
+
+// OrderRefunder marks the order of a refunded payment as refunded and gives the redemption of its coupon back
+// at the same time. An order that is already refunded is left as it is, so the redemption is only given back once
+type OrderRefunder interface {
+	Refund(ctx context.Context, orderID string) error
+}
+
+// PaymentEventType is the kind of state change a PaymentEvent notifies about
+type PaymentEventType string
+
+// List of payment event types the payment processor can notify about
+const (
+	PaymentEventCaptured PaymentEventType = "payment.captured"
+	PaymentEventRefunded PaymentEventType = "payment.refunded"
+)
+
+// PaymentEvent is a notification from the payment processor about a change in the state of a payment.
+// RefundedAmount is the total amount refunded so far, not the amount of the latest refund
+type PaymentEvent struct {
+	ID             string           `firestore:"-" json:"id"`
+	Type           PaymentEventType `firestore:"type" json:"type"`
+	ExternalID     string           `firestore:"externalId" json:"externalId"`
+	RefundedAmount int64            `firestore:"refundedAmount" json:"refundedAmount"`
+	Received       int64            `firestore:"received" json:"-"`
+}
+
+// PaymentEventRepository keeps track of the payment events that has been processed
+type PaymentEventRepository interface {
+	Get(ctx context.Context, id string) (*PaymentEvent, error)
+	Create(ctx context.Context, event *PaymentEvent) error
+}
+
+// PaymentDeclinedError is returned by a PaymentProvider when the payment processor declines a payment
+type PaymentDeclinedError struct {
+	Reason string
+}
+
+func (e *PaymentDeclinedError) Error() string {
+	return fmt.Sprintf("The payment was declined: %s", e.Reason)
 }
@@ -0,0 +1,167 @@
+package checkout
+
+import (
+	"context"
+	"log"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/pricing"
+)
+
+// Service turns a cart into an order with a payment attached to it
+type Service struct {
+	orderReader       minicommerce.OrderReader
+	orderPlacer       minicommerce.OrderPlacer
+	productReader     minicommerce.ProductReader
+	paymentRepository minicommerce.PaymentRepository
+	paymentProvider   minicommerce.PaymentProvider
+	couponValidator   minicommerce.CouponValidator
+	idGenerator       minicommerce.IDGenerator
+	rules             pricing.Rules
+}
+
+// NewService is the constructor for the checkout Service
+func NewService(orderReader minicommerce.OrderReader,
+	orderPlacer minicommerce.OrderPlacer,
+	productReader minicommerce.ProductReader,
+	paymentRepository minicommerce.PaymentRepository,
+	paymentProvider minicommerce.PaymentProvider,
+	couponValidator minicommerce.CouponValidator,
+	idGenerator minicommerce.IDGenerator,
+	rules pricing.Rules) *Service {
+
+	return &Service{
+		orderReader:       orderReader,
+		orderPlacer:       orderPlacer,
+		productReader:     productReader,
+		paymentRepository: paymentRepository,
+		paymentProvider:   paymentProvider,
+		couponValidator:   couponValidator,
+		idGenerator:       idGenerator,
+		rules:             rules,
+	}
+}
+
+// Checkout locks the prices of the items in the cart, calculates the totals of the order with the discount
+// of the coupon on it and authorizes and captures the payment for it. The order is placed with a pending payment
+// before the processor is asked for the money, so a checkout that is retried or runs twice can never charge
+// the customer twice. The coupon is redeemed as the order is placed and released again if the payment fails.
+// A capture that is still pending is marked as paid when the processor confirms it
+func (s *Service) Checkout(ctx context.Context, orderID string) (*minicommerce.Order, error) {
+	order, err := s.orderReader.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.PaymentID != "" {
+		return nil, &minicommerce.AlreadyCheckedOutError{OrderID: order.ID}
+	}
+
+	if len(order.Items) == 0 {
+		return nil, &EmptyCartError{order.ID}
+	}
+
+	if err := s.lockPrices(ctx, order); err != nil {
+		return nil, err
+	}
+
+	var coupon *minicommerce.Coupon
+	if order.Coupon != "" {
+		coupon, err = s.couponValidator.Validate(ctx, order.Coupon, order)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pricing.Calculate(order, coupon, s.rules)
+
+	payment, err := s.place(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.charge(ctx, order, payment); err != nil {
+		return nil, err
+	}
+
+	// the money has been captured, so the order stays placed even if the result can't be saved.
+	// The payment already has its ExternalID, so the webhook of the processor will mark it as paid
+	if err := s.paymentRepository.UpdateCharge(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// place attaches a new pending payment for the total of the order to it and redeems the coupon of the order
+func (s *Service) place(ctx context.Context, order *minicommerce.Order) (*minicommerce.Payment, error) {
+	id, err := s.idGenerator.New()
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &minicommerce.Payment{
+		ID:      id,
+		OrderID: order.ID,
+		Amount:  order.Total,
+	}
+
+	if err := s.orderPlacer.Place(ctx, order, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// charge authorizes and captures the payment of the placed order. The ExternalID is saved before the capture,
+// so the payment can be found when the processor notifies about it. When the payment fails the authorization
+// is voided and the order is given back as a cart with the redemption of its coupon. An authorization that
+// can't be voided may still be captured, so the order keeps its payment and the webhook of the processor settles it
+func (s *Service) charge(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) error {
+	if err := s.paymentProvider.Authorize(ctx, payment); err != nil {
+		s.cancel(ctx, order, payment)
+		return err
+	}
+
+	err := s.paymentRepository.UpdateCharge(ctx, payment)
+	if err == nil {
+		err = s.paymentProvider.Capture(ctx, payment)
+	}
+	if err != nil {
+		if voidErr := s.paymentProvider.Void(ctx, payment); voidErr != nil {
+			log.Printf("could not void the payment %s of order %s: %v", payment.ID, order.ID, voidErr)
+			return err
+		}
+
+		s.cancel(ctx, order, payment)
+		return err
+	}
+
+	return nil
+}
+
+// cancel gives the order back as a cart. Should cancelling fail, the order keeps a payment that is never paid
+func (s *Service) cancel(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) {
+	if err := s.orderPlacer.Cancel(ctx, order, payment); err != nil {
+		log.Printf("could not cancel the payment %s of order %s: %v", payment.ID, order.ID, err)
+	}
+}
+
+// lockPrices replaces the items in the cart with the current version of the product,
+// so the customer pays the price the product has at the time of checkout
+func (s *Service) lockPrices(ctx context.Context, order *minicommerce.Order) error {
+	for i, item := range order.Items {
+		product, err := s.productReader.Get(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+
+		if !product.Active {
+			return &ProductUnavailableError{product.ID}
+		}
+
+		order.Items[i] = *product
+	}
+
+	return nil
+}
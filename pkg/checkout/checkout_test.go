@@ -0,0 +1,382 @@
+package checkout
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/fakepayment"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/eikc/minicommerce/pkg/pricing"
+	"github.com/golang/mock/gomock"
+)
+
+func setupCheckoutService(t *testing.T) (*Service, *mocks.MockOrderRepository,
+	*mocks.MockOrderPlacer,
+	*mocks.MockProductRepository,
+	*mocks.MockPaymentRepository,
+	*fakepayment.Provider,
+	*mocks.MockCouponValidator,
+	*mocks.MockIDGenerator,
+	func()) {
+
+	ctrl := gomock.NewController(t)
+	orders := mocks.NewMockOrderRepository(ctrl)
+	placer := mocks.NewMockOrderPlacer(ctrl)
+	products := mocks.NewMockProductRepository(ctrl)
+	payments := mocks.NewMockPaymentRepository(ctrl)
+	provider := fakepayment.NewProvider()
+	coupons := mocks.NewMockCouponValidator(ctrl)
+	ids := mocks.NewMockIDGenerator(ctrl)
+
+	service := NewService(orders, placer, products, payments, provider, coupons, ids, pricing.DefaultRules())
+
+	return service, orders, placer, products, payments, provider, coupons, ids, func() {
+		ctrl.Finish()
+	}
+}
+
+// placeOrder makes the placer attach the payment to the order like the repository does
+func placeOrder(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) {
+	order.PaymentID = payment.ID
+}
+
+// cancelOrder makes the placer take the payment off the order like the repository does
+func cancelOrder(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) {
+	order.PaymentID = ""
+}
+
+func TestCheckout(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		delayCaptures bool
+	}{
+		{
+			desc: "Checking out will capture the payment and attach it to the order",
+		},
+		{
+			desc:          "When the capture is delayed, the payment is attached but not paid",
+			delayCaptures: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			service, orders, placer, products, payments, provider, _, ids, finalize := setupCheckoutService(t)
+			defer finalize()
+
+			provider.DelayCaptures(tC.delayCaptures)
+
+			cart := minicommerce.Order{
+				ID: "cart",
+				Items: []minicommerce.Product{
+					{ID: "product-one", Price: 10000, Active: true},
+					{ID: "product-two", Price: 5000, Active: true},
+				},
+			}
+
+			orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+			products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Name: "one", Price: 12000, Active: true}, nil)
+			products.EXPECT().Get(gomock.Any(), "product-two").Times(1).Return(&minicommerce.Product{ID: "product-two", Name: "two", Price: 4999, Active: true}, nil)
+			ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+			var placed minicommerce.Order
+			placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(ctx context.Context, o *minicommerce.Order, p *minicommerce.Payment) {
+				placeOrder(ctx, o, p)
+				placed = *o
+			}).Times(1).Return(nil)
+
+			// the payment is saved with its external ID before the capture, and once more with the result of it
+			var payment minicommerce.Payment
+			payments.EXPECT().UpdateCharge(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, p *minicommerce.Payment) {
+				payment = *p
+			}).Times(2).Return(nil)
+
+			if _, err := service.Checkout(context.Background(), "cart"); err != nil {
+				t.Error(err.Error())
+			}
+
+			cupaloy.SnapshotT(t, payment, placed)
+		})
+	}
+}
+
+func TestCheckoutErrors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		order   minicommerce.Order
+		product *minicommerce.Product
+	}{
+		{
+			desc: "An order that already has a payment can not be checked out again",
+			order: minicommerce.Order{
+				ID:        "paid-order",
+				PaymentID: "payment-id",
+				Items:     []minicommerce.Product{{ID: "product-one"}},
+			},
+		},
+		{
+			desc: "An empty cart can not be checked out",
+			order: minicommerce.Order{
+				ID: "empty-cart",
+			},
+		},
+		{
+			desc: "A cart with an inactive product can not be checked out",
+			order: minicommerce.Order{
+				ID:    "inactive-cart",
+				Items: []minicommerce.Product{{ID: "product-one", Active: true}},
+			},
+			product: &minicommerce.Product{ID: "product-one", Active: false},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			service, orders, _, products, _, _, _, _, finalize := setupCheckoutService(t)
+			defer finalize()
+
+			order := tC.order
+			orders.EXPECT().Get(gomock.Any(), order.ID).Times(1).Return(&order, nil)
+			if tC.product != nil {
+				products.EXPECT().Get(gomock.Any(), tC.product.ID).Times(1).Return(tC.product, nil)
+			}
+
+			_, err := service.Checkout(context.Background(), order.ID)
+			if err == nil {
+				t.Fatal("expected the checkout to fail")
+			}
+
+			cupaloy.SnapshotT(t, err.Error())
+		})
+	}
+}
+
+func TestCheckoutDeclined(t *testing.T) {
+	service, orders, placer, products, _, provider, _, ids, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	provider.DeclineAmount(12500, "insufficient funds")
+
+	cart := minicommerce.Order{
+		ID:    "cart",
+		Items: []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+	gomock.InOrder(
+		placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Do(placeOrder).Times(1).Return(nil),
+		placer.EXPECT().Cancel(gomock.Any(), gomock.Any(), gomock.Any()).Do(cancelOrder).Times(1).Return(nil),
+	)
+
+	_, err := service.Checkout(context.Background(), "cart")
+	if _, ok := err.(*minicommerce.PaymentDeclinedError); !ok {
+		t.Fatalf("expected the payment to be declined, got %v", err)
+	}
+}
+
+func TestCheckoutPlacedConcurrently(t *testing.T) {
+	service, orders, placer, products, _, _, _, ids, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	cart := minicommerce.Order{
+		ID:    "cart",
+		Items: []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	placed := &minicommerce.AlreadyCheckedOutError{OrderID: "cart"}
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+	// the other checkout placed the order first, so this one never asks the processor for the money
+	placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(placed)
+
+	if _, err := service.Checkout(context.Background(), "cart"); err != placed {
+		t.Errorf("expected the order to be checked out already, got %v", err)
+	}
+}
+
+func TestCheckoutVoidedWhenNotSaved(t *testing.T) {
+	service, orders, placer, products, payments, provider, _, ids, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	cart := minicommerce.Order{
+		ID:    "cart",
+		Items: []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	unavailable := errors.New("firestore is unavailable")
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+	var authorized minicommerce.Payment
+	gomock.InOrder(
+		placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Do(placeOrder).Times(1).Return(nil),
+		payments.EXPECT().UpdateCharge(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, p *minicommerce.Payment) {
+			authorized = *p
+		}).Times(1).Return(unavailable),
+		placer.EXPECT().Cancel(gomock.Any(), gomock.Any(), gomock.Any()).Do(cancelOrder).Times(1).Return(nil),
+	)
+
+	if _, err := service.Checkout(context.Background(), "cart"); err != unavailable {
+		t.Fatalf("expected the checkout to fail with the error saving the payment, got %v", err)
+	}
+
+	if err := provider.Capture(context.Background(), &authorized); err == nil {
+		t.Error("expected the authorization to be voided")
+	}
+}
+
+func TestCheckoutKeptWhenNotVoided(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orders := mocks.NewMockOrderRepository(ctrl)
+	placer := mocks.NewMockOrderPlacer(ctrl)
+	products := mocks.NewMockProductRepository(ctrl)
+	payments := mocks.NewMockPaymentRepository(ctrl)
+	provider := mocks.NewMockPaymentProvider(ctrl)
+	ids := mocks.NewMockIDGenerator(ctrl)
+	service := NewService(orders, placer, products, payments, provider, mocks.NewMockCouponValidator(ctrl), ids, pricing.DefaultRules())
+
+	cart := minicommerce.Order{
+		ID:    "cart",
+		Items: []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	timeout := errors.New("the processor timed out")
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+	// the capture may have gone through, so the order must not be cancelled when the authorization can't be voided
+	gomock.InOrder(
+		placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Do(placeOrder).Times(1).Return(nil),
+		provider.EXPECT().Authorize(gomock.Any(), gomock.Any()).Times(1).Return(nil),
+		payments.EXPECT().UpdateCharge(gomock.Any(), gomock.Any()).Times(1).Return(nil),
+		provider.EXPECT().Capture(gomock.Any(), gomock.Any()).Times(1).Return(timeout),
+		provider.EXPECT().Void(gomock.Any(), gomock.Any()).Times(1).Return(errors.New("the payment is captured")),
+	)
+	placer.EXPECT().Cancel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if _, err := service.Checkout(context.Background(), "cart"); err != timeout {
+		t.Fatalf("expected the checkout to fail with the error capturing the payment, got %v", err)
+	}
+
+	if cart.PaymentID != "payment-id" {
+		t.Errorf("expected the order to keep its payment, got %q", cart.PaymentID)
+	}
+}
+
+func TestCheckoutCoupon(t *testing.T) {
+	service, orders, placer, products, payments, _, validator, ids, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	cart := minicommerce.Order{
+		ID:     "cart",
+		Coupon: "TENOFF",
+		Items:  []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	validator.EXPECT().Validate(gomock.Any(), "TENOFF", gomock.Any()).Times(1).Return(&minicommerce.Coupon{ID: "TENOFF", Active: true, PercentOff: 10}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+	placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Do(placeOrder).Times(1).Return(nil)
+	payments.EXPECT().UpdateCharge(gomock.Any(), gomock.Any()).Times(2).Return(nil)
+
+	order, err := service.Checkout(context.Background(), "cart")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if order.Discount != 1000 || order.Total != 11250 {
+		t.Errorf("expected a discount of 1000 and a total of 11250, got %d and %d", order.Discount, order.Total)
+	}
+}
+
+func TestCheckoutCouponRejected(t *testing.T) {
+	service, orders, _, products, _, _, validator, _, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	cart := minicommerce.Order{
+		ID:     "cart",
+		Coupon: "EXPIRED",
+		Items:  []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	rejection := &coupons.RejectionError{Code: "EXPIRED", Reason: coupons.ReasonExpired}
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	validator.EXPECT().Validate(gomock.Any(), "EXPIRED", gomock.Any()).Times(1).Return(nil, rejection)
+
+	if _, err := service.Checkout(context.Background(), "cart"); err != rejection {
+		t.Errorf("expected the coupon to be rejected, got %v", err)
+	}
+}
+
+func TestCheckoutCouponExhausted(t *testing.T) {
+	service, orders, placer, products, _, _, validator, ids, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	cart := minicommerce.Order{
+		ID:     "cart",
+		Coupon: "TENOFF",
+		Items:  []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	validator.EXPECT().Validate(gomock.Any(), "TENOFF", gomock.Any()).Times(1).Return(&minicommerce.Coupon{ID: "TENOFF", Active: true, PercentOff: 10}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+	// the coupon is redeemed as the order is placed, so an exhausted coupon keeps the order from being placed
+	placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&minicommerce.CouponExhaustedError{Code: "TENOFF"})
+
+	_, err := service.Checkout(context.Background(), "cart")
+	if _, ok := err.(*minicommerce.CouponExhaustedError); !ok {
+		t.Errorf("expected the coupon to be exhausted, got %v", err)
+	}
+}
+
+func TestCheckoutCouponReleasedWhenDeclined(t *testing.T) {
+	service, orders, placer, products, _, provider, validator, ids, finalize := setupCheckoutService(t)
+	defer finalize()
+
+	provider.DeclineAmount(11250, "insufficient funds")
+
+	cart := minicommerce.Order{
+		ID:     "cart",
+		Coupon: "TENOFF",
+		Items:  []minicommerce.Product{{ID: "product-one", Price: 10000, Active: true}},
+	}
+
+	orders.EXPECT().Get(gomock.Any(), "cart").Times(1).Return(&cart, nil)
+	products.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Price: 10000, Active: true}, nil)
+	validator.EXPECT().Validate(gomock.Any(), "TENOFF", gomock.Any()).Times(1).Return(&minicommerce.Coupon{ID: "TENOFF", Active: true, PercentOff: 10}, nil)
+	ids.EXPECT().New().Times(1).Return("payment-id", nil)
+
+	// cancelling the placed order gives the redemption of its coupon back
+	var cancelled minicommerce.Order
+	gomock.InOrder(
+		placer.EXPECT().Place(gomock.Any(), gomock.Any(), gomock.Any()).Do(placeOrder).Times(1).Return(nil),
+		placer.EXPECT().Cancel(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(ctx context.Context, o *minicommerce.Order, p *minicommerce.Payment) {
+			cancelled = *o
+		}).Times(1).Return(nil),
+	)
+
+	_, err := service.Checkout(context.Background(), "cart")
+	if _, ok := err.(*minicommerce.PaymentDeclinedError); !ok {
+		t.Fatalf("expected the payment to be declined, got %v", err)
+	}
+
+	if cancelled.Coupon != "TENOFF" {
+		t.Errorf("expected the order with the coupon to be cancelled, got %v", cancelled)
+	}
+}
@@ -0,0 +1,23 @@
+package checkout
+
+import (
+	"fmt"
+)
+
+// EmptyCartError is returned when an order without any items is checked out
+type EmptyCartError struct {
+	orderID string
+}
+
+func (e *EmptyCartError) Error() string {
+	return fmt.Sprintf("The order: %s does not contain any items", e.orderID)
+}
+
+// ProductUnavailableError is returned when an item in the cart can no longer be bought
+type ProductUnavailableError struct {
+	productID string
+}
+
+func (e *ProductUnavailableError) Error() string {
+	return fmt.Sprintf("The product: %s is no longer available", e.productID)
+}
@@ -0,0 +1,114 @@
+package coupons
+
+import (
+	"context"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/pricing"
+)
+
+// Service validates coupons against the rules configured on them
+type Service struct {
+	couponReader minicommerce.CouponReader
+	orderReader  minicommerce.CustomerOrderReader
+	timeService  minicommerce.TimeService
+}
+
+// NewService is the constructor for the coupon Service
+func NewService(couponReader minicommerce.CouponReader,
+	orderReader minicommerce.CustomerOrderReader,
+	timeService minicommerce.TimeService) *Service {
+
+	return &Service{
+		couponReader: couponReader,
+		orderReader:  orderReader,
+		timeService:  timeService,
+	}
+}
+
+// Validate gets the coupon with the given code and checks that it can be redeemed on the order right now.
+// A coupon that can't be redeemed is rejected with a RejectionError
+func (s *Service) Validate(ctx context.Context, code string, order *minicommerce.Order) (*minicommerce.Coupon, error) {
+	coupon, err := s.couponReader.GetByCode(ctx, code)
+	if _, ok := err.(*firestore.DocumentNotFoundError); ok {
+		return nil, &RejectionError{code, ReasonNotFound}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Check(coupon, s.timeService.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := CheckOrder(coupon, order); err != nil {
+		return nil, err
+	}
+
+	if coupon.FirstOrderOnly {
+		if err := s.checkFirstOrder(ctx, coupon, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return coupon, nil
+}
+
+// CheckOrder checks that the order reaches the minimum amount of the coupon
+// and contains at least one item the coupon gives a discount on
+func CheckOrder(coupon *minicommerce.Coupon, order *minicommerce.Order) error {
+	if pricing.Amount(order.Items) < coupon.MinimumAmount {
+		return &RejectionError{coupon.ID, ReasonMinimumAmount}
+	}
+
+	for _, item := range order.Items {
+		if pricing.Eligible(item, coupon) {
+			return nil
+		}
+	}
+
+	return &RejectionError{coupon.ID, ReasonNotApplicable}
+}
+
+// checkFirstOrder makes sure the customer has not checked out any other order,
+// an order without an email can't be matched to a customer and is rejected
+func (s *Service) checkFirstOrder(ctx context.Context, coupon *minicommerce.Coupon, order *minicommerce.Order) error {
+	if order.Customer.Email == "" {
+		return &RejectionError{coupon.ID, ReasonFirstOrderOnly}
+	}
+
+	orders, err := s.orderReader.GetByCustomerEmail(ctx, order.Customer.Email)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		if o.ID != order.ID && o.PaymentID != "" {
+			return &RejectionError{coupon.ID, ReasonFirstOrderOnly}
+		}
+	}
+
+	return nil
+}
+
+// Check checks the coupon against the unix time now. RedeemBy is the last second the coupon can be
+// redeemed in, while the coupon can only be redeemed before RedeemBefore.
+// A coupon giving both an amount and a percentage off is rejected, as only one of them can be applied to an order
+func Check(coupon *minicommerce.Coupon, now int64) error {
+	switch {
+	case !coupon.Active:
+		return &RejectionError{coupon.ID, ReasonInactive}
+	case coupon.AmountOff > 0 && coupon.PercentOff > 0:
+		return &RejectionError{coupon.ID, ReasonInvalid}
+	case coupon.RedeemBy > 0 && now > coupon.RedeemBy:
+		return &RejectionError{coupon.ID, ReasonExpired}
+	case coupon.RedeemBefore > 0 && now >= coupon.RedeemBefore:
+		return &RejectionError{coupon.ID, ReasonExpired}
+	case coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions:
+		return &RejectionError{coupon.ID, ReasonExhausted}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package coupons
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCheck(t *testing.T) {
+	const now int64 = 1000
+
+	testCases := []struct {
+		desc   string
+		coupon minicommerce.Coupon
+		reason Reason
+	}{
+		{
+			desc:   "An active coupon without any limits can be redeemed",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500},
+		},
+		{
+			desc:   "An inactive coupon is rejected",
+			coupon: minicommerce.Coupon{ID: "code", AmountOff: 500},
+			reason: ReasonInactive,
+		},
+		{
+			desc:   "A coupon giving both an amount and a percentage off is rejected",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, PercentOff: 10},
+			reason: ReasonInvalid,
+		},
+		{
+			desc:   "A coupon can be redeemed in the second of RedeemBy",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, PercentOff: 10, RedeemBy: now},
+		},
+		{
+			desc:   "A coupon is expired after RedeemBy",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, PercentOff: 10, RedeemBy: now - 1},
+			reason: ReasonExpired,
+		},
+		{
+			desc:   "A coupon can be redeemed before RedeemBefore",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, PercentOff: 10, RedeemBefore: now + 1},
+		},
+		{
+			desc:   "A coupon is expired in the second of RedeemBefore",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, PercentOff: 10, RedeemBefore: now},
+			reason: ReasonExpired,
+		},
+		{
+			desc:   "A coupon below the redemption cap can be redeemed",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, MaxRedemptions: 2, Redemptions: 1},
+		},
+		{
+			desc:   "A coupon that reached the redemption cap is rejected",
+			coupon: minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, MaxRedemptions: 2, Redemptions: 2},
+			reason: ReasonExhausted,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := Check(&tC.coupon, now)
+			if tC.reason == "" {
+				if err != nil {
+					t.Errorf("expected the coupon to be redeemable, got %v", err)
+				}
+				return
+			}
+
+			rejection, ok := err.(*RejectionError)
+			if !ok {
+				t.Fatalf("expected a RejectionError, got %v", err)
+			}
+
+			if rejection.Reason != tC.reason {
+				t.Errorf("expected the reason %s, got %s", tC.reason, rejection.Reason)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	order := minicommerce.Order{
+		ID:       "order-one",
+		Customer: minicommerce.Customer{Email: "customer@example.com"},
+		Items: []minicommerce.Product{
+			{ID: "product-one", Type: minicommerce.ProductTypeDigital, Price: 10000},
+			{ID: "product-two", Type: minicommerce.ProductTypeShippable, Price: 5000},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		coupon         *minicommerce.Coupon
+		err            error
+		order          minicommerce.Order
+		customerOrders []minicommerce.Order
+		reason         Reason
+	}{
+		{
+			desc:   "A redeemable coupon is returned",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, RedeemBy: 2000},
+			order:  order,
+		},
+		{
+			desc:   "An expired coupon is rejected",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, RedeemBy: 500},
+			order:  order,
+			reason: ReasonExpired,
+		},
+		{
+			desc:   "A coupon that does not exist is rejected",
+			err:    &firestore.DocumentNotFoundError{},
+			order:  order,
+			reason: ReasonNotFound,
+		},
+		{
+			desc:   "An order below the minimum amount is rejected",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, MinimumAmount: 20000},
+			order:  order,
+			reason: ReasonMinimumAmount,
+		},
+		{
+			desc:   "An order reaching the minimum amount is accepted",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, MinimumAmount: 15000},
+			order:  order,
+		},
+		{
+			desc:   "An order without any eligible items is rejected",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, ProductTypes: []minicommerce.ProductType{minicommerce.ProductTypeLink}},
+			order:  order,
+			reason: ReasonNotApplicable,
+		},
+		{
+			desc:   "An order with an eligible item is accepted",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, ProductIDs: []string{"product-two"}},
+			order:  order,
+		},
+		{
+			desc:           "A first order coupon is accepted on the first order of the customer",
+			coupon:         &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, FirstOrderOnly: true},
+			order:          order,
+			customerOrders: []minicommerce.Order{{ID: "order-one"}, {ID: "abandoned-cart"}},
+		},
+		{
+			desc:           "A first order coupon is rejected when the customer has checked out before",
+			coupon:         &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, FirstOrderOnly: true},
+			order:          order,
+			customerOrders: []minicommerce.Order{{ID: "order-one"}, {ID: "previous-order", PaymentID: "payment-one"}},
+			reason:         ReasonFirstOrderOnly,
+		},
+		{
+			desc:   "A first order coupon is rejected when the order has no email",
+			coupon: &minicommerce.Coupon{ID: "code", Active: true, AmountOff: 500, FirstOrderOnly: true},
+			order:  minicommerce.Order{ID: "order-one", Items: order.Items},
+			reason: ReasonFirstOrderOnly,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			coupons := mocks.NewMockCouponReader(ctrl)
+			orders := mocks.NewMockCustomerOrderReader(ctrl)
+			time := mocks.NewMockTimeService(ctrl)
+			coupons.EXPECT().GetByCode(gomock.Any(), "code").Times(1).Return(tC.coupon, tC.err)
+			time.EXPECT().Now().AnyTimes().Return(int64(1000))
+			if tC.customerOrders != nil {
+				orders.EXPECT().GetByCustomerEmail(gomock.Any(), tC.order.Customer.Email).Times(1).Return(tC.customerOrders, nil)
+			}
+
+			service := NewService(coupons, orders, time)
+			coupon, err := service.Validate(context.Background(), "code", &tC.order)
+			if tC.reason == "" {
+				if err != nil {
+					t.Fatalf("expected the coupon to be redeemable, got %v", err)
+				}
+				if coupon.ID != "code" {
+					t.Errorf("expected the coupon code, got %s", coupon.ID)
+				}
+				return
+			}
+
+			rejection, ok := err.(*RejectionError)
+			if !ok {
+				t.Fatalf("expected a RejectionError, got %v", err)
+			}
+
+			if rejection.Reason != tC.reason {
+				t.Errorf("expected the reason %s, got %s", tC.reason, rejection.Reason)
+			}
+		})
+	}
+}
+
+func TestValidateRepositoryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	coupons := mocks.NewMockCouponReader(ctrl)
+	orders := mocks.NewMockCustomerOrderReader(ctrl)
+	time := mocks.NewMockTimeService(ctrl)
+	coupons.EXPECT().GetByCode(gomock.Any(), "code").Times(1).Return(nil, errors.New("some test error occurred"))
+
+	service := NewService(coupons, orders, time)
+	_, err := service.Validate(context.Background(), "code", &minicommerce.Order{})
+	if _, ok := err.(*RejectionError); ok || err == nil {
+		t.Errorf("expected the repository error to be returned, got %v", err)
+	}
+}
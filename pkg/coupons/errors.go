@@ -0,0 +1,31 @@
+package coupons
+
+import (
+	"fmt"
+)
+
+// Reason describes why a coupon was rejected
+type Reason string
+
+// The reasons a coupon can be rejected for
+const (
+	ReasonNotFound  Reason = "not_found"
+	ReasonInactive  Reason = "inactive"
+	ReasonExpired   Reason = "expired"
+	ReasonExhausted Reason = "exhausted"
+	ReasonInvalid   Reason = "invalid"
+
+	ReasonMinimumAmount  Reason = "minimum_amount"
+	ReasonNotApplicable  Reason = "not_applicable"
+	ReasonFirstOrderOnly Reason = "first_order_only"
+)
+
+// RejectionError is returned when a coupon can not be redeemed
+type RejectionError struct {
+	Code   string
+	Reason Reason
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("The coupon: %s can not be redeemed: %s", e.Code, e.Reason)
+}
@@ -0,0 +1,91 @@
+package coupons
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/eikc/minicommerce"
+)
+
+// alphabet leaves out 0, O, 1 and I so codes can't be misread when they are typed in.
+// It has 32 characters, so every random byte maps to a character without any bias
+const alphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// codeLength gives 32^10 possible codes, enough to make collisions between campaigns practically impossible
+const codeLength = 10
+
+// MaxGenerate is the maximum number of coupons that can be generated at once
+const MaxGenerate = 10000
+
+// Generator creates single use coupons with random codes for campaigns
+type Generator struct {
+	couponWriter minicommerce.CouponBatchWriter
+	idGenerator  minicommerce.IDGenerator
+	random       io.Reader
+}
+
+// NewGenerator is the constructor for the coupon Generator
+func NewGenerator(couponWriter minicommerce.CouponBatchWriter, idGenerator minicommerce.IDGenerator) *Generator {
+	return &Generator{
+		couponWriter: couponWriter,
+		idGenerator:  idGenerator,
+		random:       rand.Reader,
+	}
+}
+
+// Generate creates count coupons from the template. Every coupon is a copy of the template that can be
+// redeemed once, the ID of the template is used as prefix for the random codes. The coupons get a new Batch,
+// so the codes can be exported again after they have been generated
+func (g *Generator) Generate(ctx context.Context, template minicommerce.Coupon, count int) ([]minicommerce.Coupon, error) {
+	if count < 1 || count > MaxGenerate {
+		return nil, fmt.Errorf("the number of coupons must be between 1 and %d", MaxGenerate)
+	}
+
+	batch, err := g.idGenerator.New()
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(map[string]bool, count)
+	coupons := make([]minicommerce.Coupon, 0, count)
+	for len(coupons) < count {
+		code, err := g.code()
+		if err != nil {
+			return nil, err
+		}
+
+		code = template.ID + code
+		if codes[code] {
+			continue
+		}
+		codes[code] = true
+
+		coupon := template
+		coupon.ID = code
+		coupon.MaxRedemptions = 1
+		coupon.Redemptions = 0
+		coupon.Batch = batch
+		coupons = append(coupons, coupon)
+	}
+
+	if err := g.couponWriter.CreateAll(ctx, coupons); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+func (g *Generator) code() (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := io.ReadFull(g.random, b); err != nil {
+		return "", err
+	}
+
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,75 @@
+package coupons
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestGenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	writer := mocks.NewMockCouponBatchWriter(ctrl)
+	ids := mocks.NewMockIDGenerator(ctrl)
+	ids.EXPECT().New().Times(1).Return("summer-batch", nil)
+
+	var written []minicommerce.Coupon
+	writer.EXPECT().CreateAll(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, c []minicommerce.Coupon) {
+		written = c
+	}).Times(1).Return(nil)
+
+	template := minicommerce.Coupon{ID: "SUMMER-", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 100, Redemptions: 3}
+	generator := NewGenerator(writer, ids)
+
+	coupons, err := generator.Generate(context.Background(), template, 1000)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(coupons) != 1000 || len(written) != 1000 {
+		t.Fatalf("expected 1000 coupons to be generated and written, got %d and %d", len(coupons), len(written))
+	}
+
+	codes := make(map[string]bool)
+	for _, c := range coupons {
+		if codes[c.ID] {
+			t.Errorf("the code %s was generated twice", c.ID)
+		}
+		codes[c.ID] = true
+
+		code := strings.TrimPrefix(c.ID, template.ID)
+		if len(code) != codeLength || strings.Trim(code, alphabet) != "" {
+			t.Errorf("the code %s is not made of %d unambiguous characters after the prefix", c.ID, codeLength)
+		}
+
+		if c.Batch != "summer-batch" {
+			t.Errorf("expected the coupon to be part of the batch, got %s", c.Batch)
+		}
+
+		if c.MaxRedemptions != 1 || c.Redemptions != 0 {
+			t.Errorf("expected a single use coupon, got %d max redemptions and %d redemptions", c.MaxRedemptions, c.Redemptions)
+		}
+
+		if c.Description != template.Description || c.PercentOff != template.PercentOff || !c.Active {
+			t.Errorf("expected the coupon to be a copy of the template, got %+v", c)
+		}
+	}
+}
+
+func TestGenerateCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	generator := NewGenerator(mocks.NewMockCouponBatchWriter(ctrl), mocks.NewMockIDGenerator(ctrl))
+
+	for _, count := range []int{0, -1, MaxGenerate + 1} {
+		if _, err := generator.Generate(context.Background(), minicommerce.Coupon{}, count); err == nil {
+			t.Errorf("expected generating %d coupons to fail", count)
+		}
+	}
+}
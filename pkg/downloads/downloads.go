@@ -0,0 +1,234 @@
+package downloads
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/eikc/minicommerce"
+)
+
+// Service gives the buyer of a paid order access to the files of the digital products in it,
+// within the limits that prevent the download links from being shared
+type Service struct {
+	orderReader        minicommerce.OrderReader
+	paymentReader      minicommerce.PaymentReader
+	downloadableReader minicommerce.DownloadableReader
+	storageStater      minicommerce.StorageStater
+	storageReader      minicommerce.StorageRangeReader
+	downloadCounter    minicommerce.DownloadCounter
+	accessLogger       minicommerce.DownloadAccessLogger
+	timeService        minicommerce.TimeService
+	limits             minicommerce.DownloadLimits
+}
+
+// NewService is the constructor for the downloads Service
+func NewService(orderReader minicommerce.OrderReader,
+	paymentReader minicommerce.PaymentReader,
+	downloadableReader minicommerce.DownloadableReader,
+	storageStater minicommerce.StorageStater,
+	storageReader minicommerce.StorageRangeReader,
+	downloadCounter minicommerce.DownloadCounter,
+	accessLogger minicommerce.DownloadAccessLogger,
+	timeService minicommerce.TimeService,
+	limits minicommerce.DownloadLimits) *Service {
+
+	return &Service{
+		orderReader:        orderReader,
+		paymentReader:      paymentReader,
+		downloadableReader: downloadableReader,
+		storageStater:      storageStater,
+		storageReader:      storageReader,
+		downloadCounter:    downloadCounter,
+		accessLogger:       accessLogger,
+		timeService:        timeService,
+		limits:             limits,
+	}
+}
+
+// Downloadables returns the downloadables of the digital products in the order, a downloadable that is part of
+// more than one product is only returned once
+func (s *Service) Downloadables(ctx context.Context, orderID string) ([]minicommerce.Downloadable, error) {
+	order, err := s.paidOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return purchased(order), nil
+}
+
+// Stat returns the downloadable and the attributes of its file, when it has been bought with the order.
+// A file that doesn't have the size of the upload returns an IntegrityError
+func (s *Service) Stat(ctx context.Context, orderID, downloadableID string) (*minicommerce.Downloadable, *minicommerce.StorageAttributes, error) {
+	downloadable, err := s.purchasedDownloadable(ctx, orderID, downloadableID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attrs, err := s.storageStater.Stat(ctx, downloadable.Location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if downloadable.Hash != "" && attrs.Size != downloadable.Size {
+		reason := fmt.Sprintf("it has %d bytes, but %d bytes were uploaded", attrs.Size, downloadable.Size)
+		return nil, nil, &IntegrityError{downloadableID: downloadable.ID, reason: reason}
+	}
+
+	return downloadable, attrs, nil
+}
+
+// Open returns a reader with the content of the downloadable from offset, when it has been bought with the order
+// and the limits of the downloads have not been reached. A signed link counts as one download the first time it is
+// used, so resuming a download or a player reading the file in chunks doesn't use up another one, while reading
+// from the middle of the file through a new link does. Every request is added to the access log. It is counted
+// once the file is opened, so a file that can't be read doesn't use up a download. A whole file is verified
+// against the hash of the upload while it is read
+func (s *Service) Open(ctx context.Context, orderID, downloadableID string, link minicommerce.DownloadLink, offset int64, client minicommerce.DownloadClient) (io.ReadCloser, error) {
+	downloadable, err := s.purchasedDownloadable(ctx, orderID, downloadableID)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.storageReader.ReadRange(ctx, downloadable.Location, offset, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.count(ctx, orderID, downloadableID, link, offset, client); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	if offset > 0 {
+		return r, nil
+	}
+
+	return verify(r, downloadable), nil
+}
+
+// Purchased reports whether the downloadable has been bought with an order that hasn't been refunded. An order whose
+// payment is still pending counts as well, as the payment can be captured at any moment
+func (s *Service) Purchased(ctx context.Context, downloadableID string) (bool, error) {
+	orders, err := s.orderReader.GetAll(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range orders {
+		order := &orders[i]
+		if order.PaymentID == "" || order.Refunded || !contains(purchased(order), downloadableID) {
+			continue
+		}
+
+		payment, err := s.paymentReader.Get(ctx, order.PaymentID)
+		if err != nil {
+			return false, err
+		}
+
+		if !payment.Refunded {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Refuse adds the refused request for the downloadable of the order to the access log
+func (s *Service) Refuse(ctx context.Context, orderID, downloadableID, reason string, client minicommerce.DownloadClient) error {
+	access := minicommerce.DownloadAccess{
+		OrderID:        orderID,
+		DownloadableID: downloadableID,
+		Time:           s.timeService.Now(),
+		IP:             client.IP,
+		UserAgent:      client.UserAgent,
+		Refused:        reason,
+	}
+
+	return s.accessLogger.Log(ctx, &access)
+}
+
+// purchasedDownloadable gets the downloadable when it has been bought with the order. The order only holds a copy
+// of the downloadable as it was at the time of the purchase, so the current one is looked up to get
+// where the file is stored now
+func (s *Service) purchasedDownloadable(ctx context.Context, orderID, downloadableID string) (*minicommerce.Downloadable, error) {
+	order, err := s.paidOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !contains(purchased(order), downloadableID) {
+		return nil, &NotPurchasedError{orderID: orderID, downloadableID: downloadableID}
+	}
+
+	return s.downloadableReader.Get(ctx, downloadableID)
+}
+
+// count counts the download through the link against the limits and adds the request to the access log
+func (s *Service) count(ctx context.Context, orderID, downloadableID string, link minicommerce.DownloadLink, offset int64, client minicommerce.DownloadClient) error {
+	access := minicommerce.DownloadAccess{
+		OrderID:        orderID,
+		DownloadableID: downloadableID,
+		Time:           s.timeService.Now(),
+		Offset:         offset,
+		IP:             client.IP,
+		UserAgent:      client.UserAgent,
+	}
+
+	if err := s.downloadCounter.Count(ctx, link, &access, s.limits); err != nil {
+		return err
+	}
+
+	return s.accessLogger.Log(ctx, &access)
+}
+
+// paidOrder gets the order when its payment has been captured and not refunded
+func (s *Service) paidOrder(ctx context.Context, orderID string) (*minicommerce.Order, error) {
+	order, err := s.orderReader.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.PaymentID == "" || order.Refunded {
+		return nil, &OrderNotPaidError{orderID}
+	}
+
+	payment, err := s.paymentReader.Get(ctx, order.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !payment.Paid || payment.Refunded {
+		return nil, &OrderNotPaidError{orderID}
+	}
+
+	return order, nil
+}
+
+// purchased collects the downloadables of the digital products in the order
+func purchased(order *minicommerce.Order) []minicommerce.Downloadable {
+	downloadables := make([]minicommerce.Downloadable, 0)
+	for _, item := range order.Items {
+		if item.Type != minicommerce.ProductTypeDigital {
+			continue
+		}
+
+		for _, d := range item.Downloadable {
+			if !contains(downloadables, d.ID) {
+				downloadables = append(downloadables, d)
+			}
+		}
+	}
+
+	return downloadables
+}
+
+func contains(downloadables []minicommerce.Downloadable, id string) bool {
+	for _, d := range downloadables {
+		if d.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,360 @@
+package downloads
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+var testLimits = minicommerce.DownloadLimits{MaxDownloads: 5, Window: 7 * 24 * 60 * 60}
+
+var testClient = minicommerce.DownloadClient{IP: "203.0.113.7", UserAgent: "curl/7.64.1"}
+
+var testLink = minicommerce.DownloadLink{Signature: "signature-of-the-link", Expires: 4600}
+
+// testAccess is the access the counter is given for a request from the test client at the unix time 1000
+func testAccess(offset int64) *minicommerce.DownloadAccess {
+	return &minicommerce.DownloadAccess{OrderID: "order-one", DownloadableID: "pdf", Time: 1000, Offset: offset, IP: testClient.IP, UserAgent: testClient.UserAgent}
+}
+
+func setupDownloadService(t *testing.T) (*Service, *mocks.MockOrderRepository,
+	*mocks.MockPaymentRepository,
+	*mocks.MockDownloadableRepository,
+	*mocks.MockStorage,
+	*mocks.MockDownloadCounter,
+	*mocks.MockDownloadAccessLogger,
+	*mocks.MockTimeService,
+	func()) {
+
+	ctrl := gomock.NewController(t)
+	orders := mocks.NewMockOrderRepository(ctrl)
+	payments := mocks.NewMockPaymentRepository(ctrl)
+	downloadables := mocks.NewMockDownloadableRepository(ctrl)
+	storage := mocks.NewMockStorage(ctrl)
+	counter := mocks.NewMockDownloadCounter(ctrl)
+	logger := mocks.NewMockDownloadAccessLogger(ctrl)
+	time := mocks.NewMockTimeService(ctrl)
+
+	service := NewService(orders, payments, downloadables, storage, storage, counter, logger, time, testLimits)
+
+	return service, orders, payments, downloadables, storage, counter, logger, time, func() {
+		ctrl.Finish()
+	}
+}
+
+func paidOrder() *minicommerce.Order {
+	return &minicommerce.Order{
+		ID:        "order-one",
+		PaymentID: "payment-one",
+		Items: []minicommerce.Product{
+			{
+				ID:   "ebook",
+				Type: minicommerce.ProductTypeDigital,
+				Downloadable: []minicommerce.Downloadable{
+					{ID: "pdf", Name: "book.pdf", Location: "book.pdf"},
+					{ID: "epub", Name: "book.epub", Location: "book.epub"},
+				},
+			},
+			{
+				ID:   "bundle",
+				Type: minicommerce.ProductTypeDigital,
+				Downloadable: []minicommerce.Downloadable{
+					{ID: "pdf", Name: "book.pdf", Location: "book.pdf"},
+				},
+			},
+			{ID: "mug", Type: minicommerce.ProductTypeShippable},
+		},
+	}
+}
+
+func TestDownloadables(t *testing.T) {
+	service, orders, payments, _, _, _, _, _, finalize := setupDownloadService(t)
+	defer finalize()
+
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+
+	downloadables, err := service.Downloadables(context.Background(), "order-one")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []minicommerce.Downloadable{
+		{ID: "pdf", Name: "book.pdf", Location: "book.pdf"},
+		{ID: "epub", Name: "book.epub", Location: "book.epub"},
+	}
+	if !reflect.DeepEqual(downloadables, expected) {
+		t.Errorf("expected the downloadables %v, got %v", expected, downloadables)
+	}
+}
+
+func TestDownloadablesNotPaid(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		order   *minicommerce.Order
+		payment *minicommerce.Payment
+	}{
+		{
+			desc:  "An order without a payment has not been paid",
+			order: &minicommerce.Order{ID: "order-one"},
+		},
+		{
+			desc:  "A refunded order is no longer paid",
+			order: &minicommerce.Order{ID: "order-one", PaymentID: "payment-one", Refunded: true},
+		},
+		{
+			desc:    "An order with a payment that is not captured yet has not been paid",
+			order:   &minicommerce.Order{ID: "order-one", PaymentID: "payment-one"},
+			payment: &minicommerce.Payment{ID: "payment-one"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			service, orders, payments, _, _, _, _, _, finalize := setupDownloadService(t)
+			defer finalize()
+
+			orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(tC.order, nil)
+			if tC.payment != nil {
+				payments.EXPECT().Get(gomock.Any(), tC.payment.ID).Times(1).Return(tC.payment, nil)
+			}
+
+			_, err := service.Downloadables(context.Background(), "order-one")
+			if _, ok := err.(*OrderNotPaidError); !ok {
+				t.Errorf("expected the order to not be paid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	service, orders, payments, downloadables, storage, counter, logger, time, finalize := setupDownloadService(t)
+	defer finalize()
+
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+	downloadables.EXPECT().Get(gomock.Any(), "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf", Location: "moved/book.pdf"}, nil)
+	storage.EXPECT().ReadRange(gomock.Any(), "moved/book.pdf", int64(0), int64(-1)).Times(1).Return(ioutil.NopCloser(strings.NewReader("the book")), nil)
+	time.EXPECT().Now().Times(1).Return(int64(1000))
+	counter.EXPECT().Count(gomock.Any(), testLink, testAccess(0), testLimits).Times(1).Return(nil)
+
+	var access minicommerce.DownloadAccess
+	logger.EXPECT().Log(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, a *minicommerce.DownloadAccess) {
+		access = *a
+	}).Times(1).Return(nil)
+
+	r, err := service.Open(context.Background(), "order-one", "pdf", testLink, 0, testClient)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(content) != "the book" {
+		t.Errorf("expected the content of the book, got %s", content)
+	}
+
+	expected := minicommerce.DownloadAccess{OrderID: "order-one", DownloadableID: "pdf", Time: 1000, IP: testClient.IP, UserAgent: testClient.UserAgent}
+	if access != expected {
+		t.Errorf("expected the access log entry %v, got %v", expected, access)
+	}
+}
+
+func TestOpenLimitReached(t *testing.T) {
+	service, orders, payments, downloadables, storage, counter, _, time, finalize := setupDownloadService(t)
+	defer finalize()
+
+	closer := &closeRecorder{Reader: strings.NewReader("the book")}
+	limit := &minicommerce.DownloadLimitError{OrderID: "order-one", DownloadableID: "pdf"}
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+	downloadables.EXPECT().Get(gomock.Any(), "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf", Location: "book.pdf"}, nil)
+	storage.EXPECT().ReadRange(gomock.Any(), "book.pdf", int64(0), int64(-1)).Times(1).Return(closer, nil)
+	time.EXPECT().Now().Times(1).Return(int64(1000))
+	counter.EXPECT().Count(gomock.Any(), testLink, testAccess(0), testLimits).Times(1).Return(limit)
+
+	if _, err := service.Open(context.Background(), "order-one", "pdf", testLink, 0, testClient); err != limit {
+		t.Errorf("expected the download limit to be reached, got %v", err)
+	}
+
+	if !closer.closed {
+		t.Error("expected the file to be closed when the download is not allowed")
+	}
+}
+
+func TestOpenResumed(t *testing.T) {
+	service, orders, payments, downloadables, storage, counter, logger, time, finalize := setupDownloadService(t)
+	defer finalize()
+
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+	downloadables.EXPECT().Get(gomock.Any(), "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf", Location: "book.pdf"}, nil)
+	storage.EXPECT().ReadRange(gomock.Any(), "book.pdf", int64(4), int64(-1)).Times(1).Return(ioutil.NopCloser(strings.NewReader("book")), nil)
+	time.EXPECT().Now().Times(1).Return(int64(1000))
+
+	// a range is counted like any other request, it is up to the counter to only count a resumed download once
+	counter.EXPECT().Count(gomock.Any(), testLink, testAccess(4), testLimits).Times(1).Return(nil)
+
+	var access minicommerce.DownloadAccess
+	logger.EXPECT().Log(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, a *minicommerce.DownloadAccess) {
+		access = *a
+	}).Times(1).Return(nil)
+
+	r, err := service.Open(context.Background(), "order-one", "pdf", testLink, 4, testClient)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	r.Close()
+
+	if access.Offset != 4 {
+		t.Errorf("expected the access to be logged with the offset 4, got %v", access)
+	}
+}
+
+func TestStat(t *testing.T) {
+	service, orders, payments, downloadables, storage, _, _, _, finalize := setupDownloadService(t)
+	defer finalize()
+
+	attrs := &minicommerce.StorageAttributes{Size: 8, ContentType: "application/pdf", ModTime: 1000}
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+	downloadables.EXPECT().Get(gomock.Any(), "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf", Location: "book.pdf"}, nil)
+	storage.EXPECT().Stat(gomock.Any(), "book.pdf").Times(1).Return(attrs, nil)
+
+	downloadable, stat, err := service.Stat(context.Background(), "order-one", "pdf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if downloadable.Name != "book.pdf" || stat != attrs {
+		t.Errorf("expected book.pdf with the attributes of its file, got %s with %v", downloadable.Name, stat)
+	}
+}
+
+// closeRecorder is a reader that remembers if it has been closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestStatSizeChanged(t *testing.T) {
+	service, orders, payments, downloadables, storage, _, _, _, finalize := setupDownloadService(t)
+	defer finalize()
+
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+	downloadables.EXPECT().Get(gomock.Any(), "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf", Location: "book.pdf", Hash: bookHash, Size: 8}, nil)
+	storage.EXPECT().Stat(gomock.Any(), "book.pdf").Times(1).Return(&minicommerce.StorageAttributes{Size: 5}, nil)
+
+	_, _, err := service.Stat(context.Background(), "order-one", "pdf")
+	if _, ok := err.(*IntegrityError); !ok {
+		t.Errorf("expected an IntegrityError, got %v", err)
+	}
+}
+
+func TestOpenNotPurchased(t *testing.T) {
+	service, orders, payments, _, _, _, _, _, finalize := setupDownloadService(t)
+	defer finalize()
+
+	orders.EXPECT().Get(gomock.Any(), "order-one").Times(1).Return(paidOrder(), nil)
+	payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(&minicommerce.Payment{ID: "payment-one", Paid: true}, nil)
+
+	_, err := service.Open(context.Background(), "order-one", "some-other-file", testLink, 0, testClient)
+	if _, ok := err.(*NotPurchasedError); !ok {
+		t.Errorf("expected the downloadable to not be purchased, got %v", err)
+	}
+}
+
+func TestPurchased(t *testing.T) {
+	refunded := paidOrder()
+	refunded.ID = "order-refunded"
+	refunded.PaymentID = "payment-refunded"
+
+	unplaced := paidOrder()
+	unplaced.ID = "order-unplaced"
+	unplaced.PaymentID = ""
+
+	testCases := []struct {
+		desc           string
+		downloadableID string
+		payment        *minicommerce.Payment
+		purchased      bool
+	}{
+		{
+			desc:           "A downloadable of a paid order is purchased",
+			downloadableID: "pdf",
+			payment:        &minicommerce.Payment{ID: "payment-one", Paid: true},
+			purchased:      true,
+		},
+		{
+			desc:           "A downloadable of an order with a pending payment is purchased",
+			downloadableID: "epub",
+			payment:        &minicommerce.Payment{ID: "payment-one"},
+			purchased:      true,
+		},
+		{
+			desc:           "A downloadable of a refunded order is not purchased",
+			downloadableID: "pdf",
+			payment:        &minicommerce.Payment{ID: "payment-one", Paid: true, Refunded: true},
+		},
+		{
+			desc:           "A downloadable of no order is not purchased",
+			downloadableID: "video",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			service, orders, payments, _, _, _, _, _, finalize := setupDownloadService(t)
+			defer finalize()
+
+			orders.EXPECT().GetAll(gomock.Any()).Times(1).Return([]minicommerce.Order{*unplaced, *refunded, *paidOrder()}, nil)
+			if tC.payment != nil {
+				payments.EXPECT().Get(gomock.Any(), "payment-refunded").Times(1).Return(&minicommerce.Payment{ID: "payment-refunded", Paid: true, Refunded: true}, nil)
+				payments.EXPECT().Get(gomock.Any(), "payment-one").Times(1).Return(tC.payment, nil)
+			}
+
+			purchased, err := service.Purchased(context.Background(), tC.downloadableID)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if purchased != tC.purchased {
+				t.Errorf("expected purchased to be %t, got %t", tC.purchased, purchased)
+			}
+		})
+	}
+}
+
+func TestRefuse(t *testing.T) {
+	service, _, _, _, _, _, logger, time, finalize := setupDownloadService(t)
+	defer finalize()
+
+	time.EXPECT().Now().Times(1).Return(int64(1000))
+	logger.EXPECT().Log(gomock.Any(), &minicommerce.DownloadAccess{
+		OrderID:        "order-one",
+		DownloadableID: "pdf",
+		Time:           1000,
+		IP:             testClient.IP,
+		UserAgent:      testClient.UserAgent,
+		Refused:        "The download link has expired",
+	}).Times(1).Return(nil)
+
+	if err := service.Refuse(context.Background(), "order-one", "pdf", "The download link has expired", testClient); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+package downloads
+
+import (
+	"fmt"
+)
+
+// OrderNotPaidError is returned when the downloads of an order that has not been paid, or has been refunded, are accessed
+type OrderNotPaidError struct {
+	orderID string
+}
+
+func (e *OrderNotPaidError) Error() string {
+	return fmt.Sprintf("The order: %s has not been paid", e.orderID)
+}
+
+// NotPurchasedError is returned when a downloadable is accessed through an order that did not buy it
+type NotPurchasedError struct {
+	orderID        string
+	downloadableID string
+}
+
+func (e *NotPurchasedError) Error() string {
+	return fmt.Sprintf("The downloadable: %s is not part of the order: %s", e.downloadableID, e.orderID)
+}
+
+// IntegrityError is returned when the stored file of a downloadable is not the file that was uploaded
+type IntegrityError struct {
+	downloadableID string
+	reason         string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("The file of the downloadable: %s is corrupt, %s", e.downloadableID, e.reason)
+}
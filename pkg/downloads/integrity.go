@@ -0,0 +1,60 @@
+package downloads
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/eikc/minicommerce"
+)
+
+// verifiedReader hashes a whole file while it is read and fails the read of the last bytes when the hash
+// doesn't match the one of the upload. Holding those bytes back makes the download short, so the client
+// never ends up with a corrupt file it thinks is complete
+type verifiedReader struct {
+	io.ReadCloser
+	downloadable *minicommerce.Downloadable
+	want         []byte
+	hash         hash.Hash
+	remaining    int64
+}
+
+// verify wraps the reader of the whole file of the downloadable. Downloadables without a hash are not verified
+func verify(r io.ReadCloser, downloadable *minicommerce.Downloadable) io.ReadCloser {
+	want, err := hex.DecodeString(downloadable.Hash)
+	if err != nil || len(want) != sha256.Size {
+		return r
+	}
+
+	return &verifiedReader{
+		ReadCloser:   r,
+		downloadable: downloadable,
+		want:         want,
+		hash:         sha256.New(),
+		remaining:    downloadable.Size,
+	}
+}
+
+func (v *verifiedReader) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	v.hash.Write(p[:n])
+	v.remaining -= int64(n)
+
+	switch {
+	case v.remaining < 0:
+		return 0, v.corrupt(fmt.Sprintf("it is larger than the %d bytes that were uploaded", v.downloadable.Size))
+	case v.remaining == 0 && !bytes.Equal(v.hash.Sum(nil), v.want):
+		return 0, v.corrupt("the SHA-256 doesn't match the upload")
+	case v.remaining > 0 && err == io.EOF:
+		return 0, v.corrupt(fmt.Sprintf("it is smaller than the %d bytes that were uploaded", v.downloadable.Size))
+	}
+
+	return n, err
+}
+
+func (v *verifiedReader) corrupt(reason string) error {
+	return &IntegrityError{downloadableID: v.downloadable.ID, reason: reason}
+}
@@ -0,0 +1,70 @@
+package downloads
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+)
+
+// bookHash is the SHA-256 of "the book"
+const bookHash = "2feb0b19d7b5676a0813f3aa2f7ab35a957686eaa84a7d34c4972334e077ce36"
+
+func TestVerify(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		downloadable minicommerce.Downloadable
+		content      string
+		corrupt      bool
+	}{
+		{
+			desc:         "A file with the hash of the upload is read as it is",
+			downloadable: minicommerce.Downloadable{ID: "pdf", Hash: bookHash, Size: 8},
+			content:      "the book",
+		},
+		{
+			desc:         "A file without a hash is not verified",
+			downloadable: minicommerce.Downloadable{ID: "pdf"},
+			content:      "the book",
+		},
+		{
+			desc:         "A file with other content of the same size is corrupt",
+			downloadable: minicommerce.Downloadable{ID: "pdf", Hash: bookHash, Size: 8},
+			content:      "the fake",
+			corrupt:      true,
+		},
+		{
+			desc:         "A file that is shorter than the upload is corrupt",
+			downloadable: minicommerce.Downloadable{ID: "pdf", Hash: bookHash, Size: 8},
+			content:      "the",
+			corrupt:      true,
+		},
+		{
+			desc:         "A file that is longer than the upload is corrupt",
+			downloadable: minicommerce.Downloadable{ID: "pdf", Hash: bookHash, Size: 8},
+			content:      "the book, second edition",
+			corrupt:      true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r := verify(ioutil.NopCloser(strings.NewReader(tC.content)), &tC.downloadable)
+
+			content, err := ioutil.ReadAll(r)
+			if tC.corrupt {
+				if _, ok := err.(*IntegrityError); !ok {
+					t.Errorf("expected an IntegrityError, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if string(content) != tC.content {
+				t.Errorf("expected %s, got %s", tC.content, content)
+			}
+		})
+	}
+}
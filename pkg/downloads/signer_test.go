@@ -0,0 +1,77 @@
+package downloads
+
+import (
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner(SigningSecret("testing-secret"))
+	signature := signer.Sign("order-one", "pdf", 1000)
+
+	testCases := []struct {
+		desc           string
+		signer         *Signer
+		orderID        string
+		downloadableID string
+		expires        int64
+		signature      string
+		valid          bool
+	}{
+		{
+			desc:           "The signature of the same link is valid",
+			signer:         signer,
+			orderID:        "order-one",
+			downloadableID: "pdf",
+			expires:        1000,
+			signature:      signature,
+			valid:          true,
+		},
+		{
+			desc:           "The signature can not be used for another order",
+			signer:         signer,
+			orderID:        "order-two",
+			downloadableID: "pdf",
+			expires:        1000,
+			signature:      signature,
+		},
+		{
+			desc:           "The signature can not be used for another downloadable",
+			signer:         signer,
+			orderID:        "order-one",
+			downloadableID: "epub",
+			expires:        1000,
+			signature:      signature,
+		},
+		{
+			desc:           "The expiry can not be extended",
+			signer:         signer,
+			orderID:        "order-one",
+			downloadableID: "pdf",
+			expires:        2000,
+			signature:      signature,
+		},
+		{
+			desc:           "A signature made with another secret is invalid",
+			signer:         NewSigner(SigningSecret("some-other-secret")),
+			orderID:        "order-one",
+			downloadableID: "pdf",
+			expires:        1000,
+			signature:      signature,
+		},
+		{
+			desc:           "Nothing is valid without a secret",
+			signer:         NewSigner(SigningSecret("")),
+			orderID:        "order-one",
+			downloadableID: "pdf",
+			expires:        1000,
+			signature:      NewSigner(SigningSecret("")).Sign("order-one", "pdf", 1000),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if valid := tC.signer.Verify(tC.orderID, tC.downloadableID, tC.expires, tC.signature); valid != tC.valid {
+				t.Errorf("expected the signature to be valid: %v, got %v", tC.valid, valid)
+			}
+		})
+	}
+}
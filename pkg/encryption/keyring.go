@@ -0,0 +1,154 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// keyLen is the length of the keys in the keyring, they are AES-256 keys
+const keyLen = 32
+
+// Keyring holds the keys files are encrypted with. New files are encrypted with the primary key, the other keys
+// are kept to decrypt the files that were encrypted before the key was rotated
+type Keyring struct {
+	primary uint32
+	keys    map[uint32][]byte
+}
+
+// keyringFile is the JSON the keyring is stored as on disk, the keys are base64 encoded
+type keyringFile struct {
+	Primary uint32       `json:"primary"`
+	Keys    []keyringKey `json:"keys"`
+}
+
+type keyringKey struct {
+	ID  uint32 `json:"id"`
+	Key string `json:"key"`
+}
+
+// UnknownKeyError is returned when a file is encrypted with a key that is not in the keyring
+type UnknownKeyError struct {
+	KeyID uint32
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("the key %d is not in the keyring", e.KeyID)
+}
+
+// LoadKeyring reads the keyring file at path
+func LoadKeyring(path string) (*Keyring, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("the keyring %s is not valid JSON: %v", path, err)
+	}
+
+	k := &Keyring{primary: f.Primary, keys: make(map[uint32][]byte, len(f.Keys))}
+	for _, key := range f.Keys {
+		b, err := base64.StdEncoding.DecodeString(key.Key)
+		if err != nil || len(b) != keyLen {
+			return nil, fmt.Errorf("the key %d in the keyring %s must be %d base64 encoded bytes", key.ID, path, keyLen)
+		}
+		k.keys[key.ID] = b
+	}
+
+	if _, ok := k.keys[k.primary]; !ok {
+		return nil, fmt.Errorf("the primary key %d is not in the keyring %s", k.primary, path)
+	}
+
+	return k, nil
+}
+
+// RotateKeyring adds a new random key to the keyring file at path and makes it the primary key, the file
+// is created when it doesn't exist. It returns the ID of the new key. The running servers keep encrypting
+// with the old key until they are restarted, which is fine, as the old key stays in the keyring
+func RotateKeyring(path string) (uint32, error) {
+	var f keyringFile
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return 0, err
+	default:
+		if err := json.Unmarshal(b, &f); err != nil {
+			return 0, fmt.Errorf("the keyring %s is not valid JSON: %v", path, err)
+		}
+	}
+
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return 0, err
+	}
+
+	id := uint32(1)
+	for _, k := range f.Keys {
+		if k.ID >= id {
+			id = k.ID + 1
+		}
+	}
+
+	f.Primary = id
+	f.Keys = append(f.Keys, keyringKey{ID: id, Key: base64.StdEncoding.EncodeToString(key)})
+
+	if err := writeKeyring(path, &f); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// writeKeyring replaces the keyring file at path, it is written next to it first, so a failing write
+// never leaves a keyring behind that is missing keys
+func writeKeyring(path string, f *keyringFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// primaryKey is the key new files are encrypted with
+func (k *Keyring) primaryKey() (uint32, []byte) {
+	return k.primary, k.keys[k.primary]
+}
+
+// key is the key with the ID
+func (k *Keyring) key(id uint32) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, &UnknownKeyError{KeyID: id}
+	}
+
+	return key, nil
+}
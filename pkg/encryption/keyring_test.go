@@ -0,0 +1,71 @@
+package encryption
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateKeyring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minicommerce-keyring")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keyring.json")
+
+	for want := uint32(1); want <= 3; want++ {
+		id, err := RotateKeyring(path)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if id != want {
+			t.Errorf("expected the new key to be %d, got %d", want, id)
+		}
+	}
+
+	keyring, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if primary, _ := keyring.primaryKey(); primary != 3 || len(keyring.keys) != 3 {
+		t.Errorf("expected the last key to be the primary of 3 keys, got %d of %d", primary, len(keyring.keys))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected only the owner to be able to read the keyring, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		keyring string
+	}{
+		{desc: "A keyring that is not JSON is invalid", keyring: "primary: 1"},
+		{desc: "A keyring without its primary key is invalid", keyring: `{"primary": 2, "keys": [{"id": 1, "key": "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="}]}`},
+		{desc: "A keyring with a short key is invalid", keyring: `{"primary": 1, "keys": [{"id": 1, "key": "c2hvcnQ="}]}`},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "keyring")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			defer os.Remove(f.Name())
+
+			f.WriteString(tC.keyring)
+			f.Close()
+
+			if _, err := LoadKeyring(f.Name()); err == nil {
+				t.Errorf("expected the keyring to be invalid")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/eikc/minicommerce"
+)
+
+// Storage encrypts the files written to the storage it wraps and decrypts them again when they are read,
+// so access to the bucket alone doesn't expose them. A file that doesn't start with the magic was stored before
+// the encryption was turned on and is read as it is, until it is written again. The attributes and the listing
+// report the size of the content, not of the encrypted file. An encrypted file can only be decrypted at the
+// location it was written to
+type Storage struct {
+	storage minicommerce.Storage
+	keyring *Keyring
+}
+
+// NewStorage wraps the storage, so the files in it are encrypted with the keys of the keyring
+func NewStorage(storage minicommerce.Storage, keyring *Keyring) *Storage {
+	return &Storage{storage: storage, keyring: keyring}
+}
+
+// Write encrypts the content of r with the primary key while it is written. An empty content type is detected
+// from the content, as the storage would only see the encrypted bytes
+func (s *Storage) Write(ctx context.Context, location string, r io.Reader, metadata *minicommerce.StorageMetadata) error {
+	keyID, key := s.keyring.primaryKey()
+	h, err := newHeader(keyID)
+	if err != nil {
+		return err
+	}
+
+	plain := bufio.NewReaderSize(r, chunkSize)
+
+	m := minicommerce.StorageMetadata{}
+	if metadata != nil {
+		m = *metadata
+	}
+	if m.ContentType == "" {
+		// Peek returns what there is when the content is shorter than 512 bytes, the error is left to the first read
+		sniff, _ := plain.Peek(512)
+		m.ContentType = http.DetectContentType(sniff)
+	}
+
+	sealed, err := newEncryptReader(plain, h, key, location)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Write(ctx, location, sealed, &m)
+}
+
+// Read decrypts the file at location while it is read
+func (s *Storage) Read(ctx context.Context, location string) (io.ReadCloser, error) {
+	return s.ReadRange(ctx, location, 0, -1)
+}
+
+// ReadRange decrypts length bytes of the file at location starting at offset, a negative length reads to the end.
+// Only the chunks with the range in them are read and decrypted
+func (s *Storage) ReadRange(ctx context.Context, location string, offset, length int64) (io.ReadCloser, error) {
+	h, key, err := s.header(ctx, location)
+	if err == errNotEncrypted {
+		return s.storage.ReadRange(ctx, location, offset, length)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := offset / chunkSize
+	sealed, err := s.storage.ReadRange(ctx, location, int64(headerLen)+index*sealedSize, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := newDecryptReader(sealed, h, key, location, uint32(index))
+	if err != nil {
+		sealed.Close()
+		return nil, err
+	}
+
+	// the start of the first chunk is decrypted to check it, but it is not part of the range
+	if _, err := io.CopyN(ioutil.Discard, d, offset%chunkSize); err != nil {
+		d.Close()
+		if err == io.EOF {
+			// the range starts after the end of the content
+			return ioutil.NopCloser(bytes.NewReader(nil)), nil
+		}
+		return nil, err
+	}
+
+	if length < 0 {
+		return d, nil
+	}
+
+	return &limitedReadCloser{Reader: io.LimitReader(d, length), Closer: d}, nil
+}
+
+// Stat gets the attributes of the file at location, with the size of its content
+func (s *Storage) Stat(ctx context.Context, location string) (*minicommerce.StorageAttributes, error) {
+	attrs, err := s.storage.Stat(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := s.contentSize(ctx, location, attrs.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := *attrs
+	stat.Size = size
+	return &stat, nil
+}
+
+// List gets the files whose location starts with the prefix. Unlike Stat, the size is the size the file takes up
+// in the storage, finding the size of the content would take a read of every file listed
+func (s *Storage) List(ctx context.Context, prefix string) ([]minicommerce.StorageObject, error) {
+	return s.storage.List(ctx, prefix)
+}
+
+// Copy decrypts the file and encrypts it again for the location it is copied to, with the primary key.
+// Unlike the wrapped storage, it downloads and uploads the file
+func (s *Storage) Copy(ctx context.Context, from, to string) error {
+	attrs, err := s.storage.Stat(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	r, err := s.Read(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return s.Write(ctx, to, r, &minicommerce.StorageMetadata{ContentType: attrs.ContentType, CacheControl: attrs.CacheControl})
+}
+
+// Delete deletes the file at location
+func (s *Storage) Delete(ctx context.Context, location string) error {
+	return s.storage.Delete(ctx, location)
+}
+
+// contentSize is the size of the content of the file at location, which is stored in size bytes
+func (s *Storage) contentSize(ctx context.Context, location string, size int64) (int64, error) {
+	if size < int64(len(magic)) {
+		return size, nil
+	}
+
+	r, err := s.storage.ReadRange(ctx, location, 0, int64(len(magic)))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	b := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+
+	if string(b) != magic {
+		return size, nil
+	}
+
+	return plainSize(size)
+}
+
+// header reads the header of the file at location and looks up the key it was encrypted with,
+// errNotEncrypted is returned for a file that is stored as it is
+func (s *Storage) header(ctx context.Context, location string) (*header, []byte, error) {
+	r, err := s.storage.ReadRange(ctx, location, 0, int64(headerLen))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := s.keyring.key(h.keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h, key, nil
+}
+
+// limitedReadCloser closes the reader a limited reader reads from
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,368 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/storage"
+)
+
+func setupEncryptedStorage(t *testing.T) (*Storage, *storage.Storage, string, func()) {
+	dir, err := ioutil.TempDir("", "minicommerce-keyring")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	path := filepath.Join(dir, "keyring.json")
+
+	if _, err := RotateKeyring(path); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	keyring, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	bucket, closeBucket, err := storage.NewStorage(context.Background(), storage.BucketURL("mem://"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return NewStorage(bucket, keyring), bucket, path, func() {
+		closeBucket()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, bucket, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	testCases := []struct {
+		desc string
+		size int
+	}{
+		{desc: "An empty file", size: 0},
+		{desc: "A file smaller than a chunk", size: 100},
+		{desc: "A file of exactly one chunk", size: chunkSize},
+		{desc: "A file of a few chunks and a bit", size: 3*chunkSize + 17},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			content := random(t, tC.size)
+			if err := s.Write(ctx, "file", bytes.NewReader(content), nil); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if got := readAll(t, s, "file", 0, -1); !bytes.Equal(got, content) {
+				t.Errorf("expected the decrypted content to be the written content")
+			}
+
+			stored := readAll(t, bucket, "file", 0, -1)
+			if len(content) > 0 && bytes.Contains(stored, content) {
+				t.Errorf("expected the stored file to be encrypted")
+			}
+
+			attrs, err := s.Stat(ctx, "file")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if attrs.Size != int64(tC.size) {
+				t.Errorf("expected the size to be %d, got %d", tC.size, attrs.Size)
+			}
+
+			objects, err := s.List(ctx, "file")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if len(objects) != 1 || objects[0].Size != int64(len(stored)) {
+				t.Errorf("expected the file to be listed with the stored size %d, got %+v", len(stored), objects)
+			}
+		})
+	}
+}
+
+func TestStorageReadRange(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	content := random(t, 2*chunkSize+100)
+	if err := s.Write(ctx, "file", bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	testCases := []struct {
+		desc   string
+		offset int64
+		length int64
+	}{
+		{desc: "A range inside the first chunk", offset: 10, length: 20},
+		{desc: "A range over the border of two chunks", offset: chunkSize - 5, length: 10},
+		{desc: "A range to the end of the file", offset: chunkSize + 7, length: -1},
+		{desc: "A range in the last chunk", offset: 2*chunkSize + 50, length: 50},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			end := int64(len(content))
+			if tC.length >= 0 {
+				end = tC.offset + tC.length
+			}
+
+			if got := readAll(t, s, "file", tC.offset, tC.length); !bytes.Equal(got, content[tC.offset:end]) {
+				t.Errorf("expected the range %d-%d of the content", tC.offset, end)
+			}
+		})
+	}
+}
+
+func TestStorageDetectsTheContentType(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	if err := s.Write(ctx, "file", strings.NewReader("%PDF-1.4 a small book"), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	attrs, err := s.Stat(ctx, "file")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if attrs.ContentType != "application/pdf" {
+		t.Errorf("expected the content type of the content, got %s", attrs.ContentType)
+	}
+}
+
+func TestStorageKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	s, bucket, path, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	if err := s.Write(ctx, "old", strings.NewReader("written with the first key"), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := RotateKeyring(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	keyring, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	rotated := NewStorage(bucket, keyring)
+
+	if err := rotated.Write(ctx, "new", strings.NewReader("written with the second key"), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if got := string(readAll(t, rotated, "old", 0, -1)); got != "written with the first key" {
+		t.Errorf("expected a file of the old key to be readable after the rotation, got %s", got)
+	}
+
+	if _, err := s.Read(ctx, "new"); err == nil {
+		t.Errorf("expected a file of the new key not to be readable without it")
+	}
+}
+
+func TestStorageTampered(t *testing.T) {
+	ctx := context.Background()
+	s, bucket, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	content := random(t, chunkSize+100)
+	if err := s.Write(ctx, "file", bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	stored := readAll(t, bucket, "file", 0, -1)
+
+	testCases := []struct {
+		desc   string
+		stored []byte
+	}{
+		{desc: "A flipped bit", stored: flip(stored, headerLen+10)},
+		{desc: "A file cut off after a whole chunk", stored: stored[:headerLen+sealedSize]},
+		{desc: "A file cut off in its header", stored: stored[:headerLen-1]},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if err := bucket.Write(ctx, "tampered", bytes.NewReader(tC.stored), nil); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			r, err := s.Read(ctx, "tampered")
+			if err == nil {
+				_, err = ioutil.ReadAll(r)
+				r.Close()
+			}
+
+			if err != ErrCorrupt {
+				t.Errorf("expected ErrCorrupt, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageMoved(t *testing.T) {
+	ctx := context.Background()
+	s, bucket, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	if err := s.Write(ctx, "file", strings.NewReader("bound to its location"), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := bucket.Copy(ctx, "file", "moved"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	r, err := s.Read(ctx, "moved")
+	if err == nil {
+		_, err = ioutil.ReadAll(r)
+		r.Close()
+	}
+
+	if err != ErrCorrupt {
+		t.Errorf("expected ErrCorrupt for a file moved in the bucket, got %v", err)
+	}
+}
+
+func TestStorageCopy(t *testing.T) {
+	ctx := context.Background()
+	s, bucket, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	testCases := []struct {
+		desc  string
+		write func(location string, content []byte) error
+	}{
+		{
+			desc: "An encrypted file is encrypted again for its new location",
+			write: func(location string, content []byte) error {
+				return s.Write(ctx, location, bytes.NewReader(content), &minicommerce.StorageMetadata{ContentType: "application/pdf"})
+			},
+		},
+		{
+			desc: "A file that is not encrypted is encrypted when it is copied",
+			write: func(location string, content []byte) error {
+				return bucket.Write(ctx, location, bytes.NewReader(content), &minicommerce.StorageMetadata{ContentType: "application/pdf"})
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			content := random(t, chunkSize+100)
+			if err := tC.write("from", content); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if err := s.Copy(ctx, "from", "to"); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if got := readAll(t, s, "to", 0, -1); !bytes.Equal(got, content) {
+				t.Errorf("expected the copy to have the content")
+			}
+
+			if stored := readAll(t, bucket, "to", 0, -1); bytes.Contains(stored, content) {
+				t.Errorf("expected the copy to be encrypted")
+			}
+
+			attrs, err := s.Stat(ctx, "to")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if attrs.ContentType != "application/pdf" {
+				t.Errorf("expected the copy to keep the content type, got %s", attrs.ContentType)
+			}
+		})
+	}
+}
+
+func TestStorageNotEncrypted(t *testing.T) {
+	ctx := context.Background()
+	s, bucket, _, finalize := setupEncryptedStorage(t)
+	defer finalize()
+
+	testCases := []struct {
+		desc    string
+		content string
+	}{
+		{desc: "An empty file", content: ""},
+		{desc: "A file shorter than the magic", content: "MC"},
+		{desc: "A file stored before the encryption", content: "stored before the encryption was turned on"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if err := bucket.Write(ctx, "plain/file", strings.NewReader(tC.content), nil); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if got := string(readAll(t, s, "plain/file", 0, -1)); got != tC.content {
+				t.Errorf("expected the file to be read as it is, got %q", got)
+			}
+
+			if len(tC.content) > 4 {
+				if got := string(readAll(t, s, "plain/file", 2, 3)); got != tC.content[2:5] {
+					t.Errorf("expected the range to be read as it is, got %q", got)
+				}
+			}
+
+			attrs, err := s.Stat(ctx, "plain/file")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if attrs.Size != int64(len(tC.content)) {
+				t.Errorf("expected the size to be %d, got %d", len(tC.content), attrs.Size)
+			}
+
+			objects, err := s.List(ctx, "plain/")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if len(objects) != 1 || objects[0].Size != int64(len(tC.content)) {
+				t.Errorf("expected the file to be listed with the size %d, got %+v", len(tC.content), objects)
+			}
+		})
+	}
+}
+
+func random(t *testing.T, size int) []byte {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return b
+}
+
+func flip(b []byte, i int) []byte {
+	flipped := append([]byte(nil), b...)
+	flipped[i] ^= 1
+	return flipped
+}
+
+func readAll(t *testing.T, s minicommerce.StorageRangeReader, location string, offset, length int64) []byte {
+	r, err := s.ReadRange(context.Background(), location, offset, length)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return b
+}
@@ -0,0 +1,247 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// A stored file starts with a header of the magic, the ID of the key and a random salt. The salt derives a key
+// for the file from the key in the keyring, so no two files share a key and the nonces can be a counter.
+// The content follows in chunks that are sealed one by one, so a file is never held in memory as a whole.
+// Every chunk authenticates the header and the location of the file, so neither can be swapped
+const (
+	magic      = "MCE1"
+	saltLen    = 16
+	headerLen  = len(magic) + 4 + saltLen
+	chunkSize  = 64 * 1024
+	tagLen     = 16
+	sealedSize = chunkSize + tagLen
+)
+
+// ErrCorrupt is returned when a file can't be decrypted, because it has been changed, cut short or moved
+var ErrCorrupt = errors.New("the encrypted file is corrupt")
+
+// errNotEncrypted is returned by readHeader when the file doesn't start with the magic
+var errNotEncrypted = errors.New("the file is not encrypted")
+
+// header is the start of an encrypted file
+type header struct {
+	keyID uint32
+	salt  []byte
+}
+
+func newHeader(keyID uint32) (*header, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	return &header{keyID: keyID, salt: salt}, nil
+}
+
+func (h *header) bytes() []byte {
+	b := make([]byte, 0, headerLen)
+	b = append(b, magic...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(magic):], h.keyID)
+	return append(b, h.salt...)
+}
+
+// readHeader reads the header at the start of r, a file that doesn't start with the magic is not encrypted
+func readHeader(r io.Reader) (*header, error) {
+	b := make([]byte, headerLen)
+	n, err := io.ReadFull(r, b)
+	if n < len(magic) || string(b[:len(magic)]) != magic {
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		return nil, errNotEncrypted
+	}
+
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, ErrCorrupt
+		}
+		return nil, err
+	}
+
+	return &header{
+		keyID: binary.BigEndian.Uint32(b[len(magic):]),
+		salt:  b[len(magic)+4:],
+	}, nil
+}
+
+// aead is the cipher of the file, keyed with the key of the keyring and the salt of the file
+func (h *header) aead(key []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(h.salt)
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData is what every chunk of the file at location authenticates besides its content
+func (h *header) additionalData(location string) []byte {
+	return append(h.bytes(), location...)
+}
+
+// nonce is the nonce of the chunk at index. The last chunk is sealed with another nonce than the others,
+// so a file that is cut off at the end of a chunk can't be decrypted
+func nonce(index uint32, last bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint32(n[7:11], index)
+	if last {
+		n[11] = 1
+	}
+
+	return n
+}
+
+// readChunk reads the next chunk of len(buf) bytes or less from r. The chunk is the last one when r has nothing after it
+func readChunk(r *bufio.Reader, buf []byte) (n int, last bool, err error) {
+	n, err = io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, true, nil
+	}
+	if err != nil {
+		return n, false, err
+	}
+
+	if _, err := r.Peek(1); err != nil {
+		if err == io.EOF {
+			return n, true, nil
+		}
+		return n, false, err
+	}
+
+	return n, false, nil
+}
+
+// encryptReader encrypts what it reads from the plain reader
+type encryptReader struct {
+	plain  *bufio.Reader
+	aead   cipher.AEAD
+	ad     []byte
+	index  uint32
+	chunk  []byte
+	sealed []byte
+	out    []byte
+	done   bool
+}
+
+func newEncryptReader(plain *bufio.Reader, h *header, key []byte, location string) (*encryptReader, error) {
+	aead, err := h.aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptReader{
+		plain:  plain,
+		aead:   aead,
+		ad:     h.additionalData(location),
+		chunk:  make([]byte, chunkSize),
+		sealed: make([]byte, 0, sealedSize),
+		out:    h.bytes(),
+	}, nil
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+
+		n, last, err := readChunk(e.plain, e.chunk)
+		if err != nil {
+			return 0, err
+		}
+
+		e.out = e.aead.Seal(e.sealed[:0], nonce(e.index, last), e.chunk[:n], e.ad)
+		e.index++
+		e.done = last
+	}
+
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// decryptReader decrypts the chunks it reads from the sealed reader, starting with the chunk at index
+type decryptReader struct {
+	sealed io.ReadCloser
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	ad     []byte
+	index  uint32
+	chunk  []byte
+	plain  []byte
+	out    []byte
+	done   bool
+}
+
+func newDecryptReader(sealed io.ReadCloser, h *header, key []byte, location string, index uint32) (*decryptReader, error) {
+	aead, err := h.aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		sealed: sealed,
+		r:      bufio.NewReaderSize(sealed, sealedSize),
+		aead:   aead,
+		ad:     h.additionalData(location),
+		index:  index,
+		chunk:  make([]byte, sealedSize),
+		plain:  make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+
+		n, last, err := readChunk(d.r, d.chunk)
+		if err != nil {
+			return 0, err
+		}
+
+		d.out, err = d.aead.Open(d.plain[:0], nonce(d.index, last), d.chunk[:n], d.ad)
+		if err != nil {
+			return 0, ErrCorrupt
+		}
+		d.index++
+		d.done = last
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptReader) Close() error {
+	return d.sealed.Close()
+}
+
+// plainSize is the size of the content of an encrypted file of size bytes
+func plainSize(size int64) (int64, error) {
+	body := size - int64(headerLen)
+	if body < tagLen {
+		return 0, ErrCorrupt
+	}
+
+	chunks := (body + sealedSize - 1) / sealedSize
+	return body - chunks*tagLen, nil
+}
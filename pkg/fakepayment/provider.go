@@ -0,0 +1,198 @@
+package fakepayment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/eikc/minicommerce"
+)
+
+type chargeState string
+
+const (
+	stateAuthorized chargeState = "authorized"
+	statePending    chargeState = "pending"
+	stateCaptured   chargeState = "captured"
+	stateVoided     chargeState = "voided"
+)
+
+type charge struct {
+	amount   int64
+	refunded int64
+	state    chargeState
+}
+
+// Provider is an in-process payment provider that never talks to a real payment processor.
+// It can be told to decline payments, delay captures and it supports partial refunds,
+// which makes it possible to test the whole checkout flow offline
+type Provider struct {
+	mu            sync.Mutex
+	counter       int
+	charges       map[string]*charge
+	declines      map[int64]string
+	delayCaptures bool
+}
+
+// NewProvider is the constructor for the fake payment Provider
+func NewProvider() *Provider {
+	return &Provider{
+		charges:  make(map[string]*charge),
+		declines: make(map[int64]string),
+	}
+}
+
+// DeclineAmount makes the provider decline every authorization of the given amount with the given reason
+func (p *Provider) DeclineAmount(amount int64, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.declines[amount] = reason
+}
+
+// DelayCaptures makes captures stay pending until they are settled with Settle,
+// just like a payment processor that confirms the capture asynchronously
+func (p *Provider) DelayCaptures(delay bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.delayCaptures = delay
+}
+
+// Authorize reserves the amount of the payment and sets the ExternalID of the payment
+func (p *Provider) Authorize(ctx context.Context, payment *minicommerce.Payment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if reason, ok := p.declines[payment.Amount]; ok {
+		return &minicommerce.PaymentDeclinedError{Reason: reason}
+	}
+
+	p.counter++
+	externalID := fmt.Sprintf("fake_%d", p.counter)
+	p.charges[externalID] = &charge{
+		amount: payment.Amount,
+		state:  stateAuthorized,
+	}
+
+	payment.ExternalID = externalID
+
+	return nil
+}
+
+// Void releases the amount of an authorized payment that is not going to be captured
+func (p *Provider) Void(ctx context.Context, payment *minicommerce.Payment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, err := p.charge(payment.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	if c.state != stateAuthorized {
+		return fmt.Errorf("fakepayment: payment %s can not be voided in state %s", payment.ExternalID, c.state)
+	}
+
+	c.state = stateVoided
+
+	return nil
+}
+
+// Capture captures an authorized payment. When captures are delayed the payment stays unpaid until it is settled
+func (p *Provider) Capture(ctx context.Context, payment *minicommerce.Payment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, err := p.charge(payment.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	if c.state != stateAuthorized {
+		return fmt.Errorf("fakepayment: payment %s can not be captured in state %s", payment.ExternalID, c.state)
+	}
+
+	c.state = stateCaptured
+	if p.delayCaptures {
+		c.state = statePending
+	}
+
+	payment.Paid = c.state == stateCaptured
+
+	return nil
+}
+
+// Settle completes a delayed capture
+func (p *Provider) Settle(externalID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, err := p.charge(externalID)
+	if err != nil {
+		return err
+	}
+
+	if c.state != statePending {
+		return fmt.Errorf("fakepayment: payment %s is not pending", externalID)
+	}
+
+	c.state = stateCaptured
+
+	return nil
+}
+
+// Refund refunds the given amount of a captured payment, multiple partial refunds are allowed
+// as long as the total refunded amount does not exceed the captured amount
+func (p *Provider) Refund(ctx context.Context, payment *minicommerce.Payment, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, err := p.charge(payment.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	if c.state != stateCaptured {
+		return fmt.Errorf("fakepayment: payment %s can not be refunded in state %s", payment.ExternalID, c.state)
+	}
+
+	if amount <= 0 || c.refunded+amount > c.amount {
+		return fmt.Errorf("fakepayment: can not refund %d of payment %s, %d is left", amount, payment.ExternalID, c.amount-c.refunded)
+	}
+
+	c.refunded += amount
+
+	payment.RefundedAmount = c.refunded
+	payment.Refunded = c.refunded == c.amount
+
+	return nil
+}
+
+// Lookup returns the state of the payment as the provider knows it
+func (p *Provider) Lookup(ctx context.Context, externalID string) (*minicommerce.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, err := p.charge(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &minicommerce.Payment{
+		ExternalID:     externalID,
+		Amount:         c.amount,
+		Paid:           c.state == stateCaptured,
+		Refunded:       c.refunded > 0 && c.refunded == c.amount,
+		RefundedAmount: c.refunded,
+	}, nil
+}
+
+func (p *Provider) charge(externalID string) (*charge, error) {
+	c, ok := p.charges[externalID]
+	if !ok {
+		return nil, fmt.Errorf("fakepayment: payment %s does not exist", externalID)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,133 @@
+package fakepayment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+)
+
+func TestProviderCapture(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProvider()
+
+	payment := minicommerce.Payment{ID: "payment", Amount: 12500}
+	if err := provider.Authorize(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := provider.Capture(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lookup, err := provider.Lookup(ctx, payment.ExternalID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, payment, lookup)
+}
+
+func TestProviderDecline(t *testing.T) {
+	provider := NewProvider()
+	provider.DeclineAmount(666, "insufficient funds")
+
+	payment := minicommerce.Payment{ID: "payment", Amount: 666}
+	err := provider.Authorize(context.Background(), &payment)
+	if _, ok := err.(*minicommerce.PaymentDeclinedError); !ok {
+		t.Fatalf("expected the payment to be declined, got %v", err)
+	}
+
+	if payment.ExternalID != "" {
+		t.Errorf("a declined payment should not get an external ID")
+	}
+}
+
+func TestProviderVoid(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProvider()
+
+	payment := minicommerce.Payment{ID: "payment", Amount: 12500}
+	if err := provider.Authorize(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := provider.Void(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := provider.Capture(ctx, &payment); err == nil {
+		t.Error("expected a voided payment to not be captured")
+	}
+
+	if err := provider.Void(ctx, &payment); err == nil {
+		t.Error("expected a payment to only be voided once")
+	}
+}
+
+func TestProviderDelayedCapture(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProvider()
+	provider.DelayCaptures(true)
+
+	payment := minicommerce.Payment{ID: "payment", Amount: 12500}
+	if err := provider.Authorize(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := provider.Capture(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if payment.Paid {
+		t.Errorf("a delayed capture should not be paid before it is settled")
+	}
+
+	if err := provider.Refund(ctx, &payment, 100); err == nil {
+		t.Errorf("a pending payment should not be refundable")
+	}
+
+	if err := provider.Settle(payment.ExternalID); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lookup, err := provider.Lookup(ctx, payment.ExternalID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !lookup.Paid {
+		t.Errorf("a settled payment should be paid")
+	}
+}
+
+func TestProviderPartialRefunds(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProvider()
+
+	payment := minicommerce.Payment{ID: "payment", Amount: 10000}
+	if err := provider.Authorize(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := provider.Capture(ctx, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := provider.Refund(ctx, &payment, 4000); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	partial := payment
+
+	if err := provider.Refund(ctx, &payment, 7000); err == nil {
+		t.Errorf("refunding more than what is left should fail")
+	}
+
+	if err := provider.Refund(ctx, &payment, 6000); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, partial, payment)
+}
@@ -2,6 +2,7 @@ package firestore
 
 import (
 	"context"
+	"fmt"
 
 	"cloud.google.com/go/firestore"
 	"github.com/eikc/minicommerce"
@@ -9,6 +10,9 @@ import (
 
 const couponsCollection string = "coupons"
 
+// maxBatchSize is the maximum number of writes firestore allows in a single batch
+const maxBatchSize = 500
+
 // CouponsRepository is the repository that communicates with the firestore database when handling coupon codes
 type CouponsRepository struct {
 	client *firestore.Client
@@ -48,6 +52,10 @@ func (c *CouponsRepository) GetAll(ctx context.Context) ([]minicommerce.Coupon,
 func (c *CouponsRepository) GetByCode(ctx context.Context, code string) (*minicommerce.Coupon, error) {
 	docRef := c.client.Collection(couponsCollection).Doc(code)
 	snapshot, err := docRef.Get(ctx)
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", couponsCollection, code)}
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +71,35 @@ func (c *CouponsRepository) GetByCode(ctx context.Context, code string) (*minico
 	return &coupon, nil
 }
 
+// GetByBatch returns the coupons that were generated together in the batch
+func (c *CouponsRepository) GetByBatch(ctx context.Context, batch string) ([]minicommerce.Coupon, error) {
+	docs, err := c.client.Collection(couponsCollection).Where("batch", "==", batch).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	coupons := make([]minicommerce.Coupon, 0, len(docs))
+	for _, d := range docs {
+		coupon := minicommerce.Coupon{
+			ID: d.Ref.ID,
+		}
+		if err := d.DataTo(&coupon); err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, coupon)
+	}
+
+	return coupons, nil
+}
+
 // Create ...
 func (c *CouponsRepository) Create(ctx context.Context, coupon minicommerce.Coupon) error {
 	docRef := c.client.Collection(couponsCollection).Doc(coupon.ID)
 	_, err := docRef.Create(ctx, coupon)
+	if isAlreadyExists(err) {
+		return &DocumentExistsError{fmt.Sprintf("%s/%s", couponsCollection, coupon.ID)}
+	}
+
 	if err != nil {
 		return err
 	}
@@ -74,13 +107,144 @@ func (c *CouponsRepository) Create(ctx context.Context, coupon minicommerce.Coup
 	return nil
 }
 
+// CreateAll creates the coupons in batched writes of at most 500 coupons. Every batch is atomic, when a batch fails
+// the batches before it are deleted again, so a failed call doesn't leave coupons behind that nobody knows the codes of.
+// A code that already exists returns a DocumentExistsError
+func (c *CouponsRepository) CreateAll(ctx context.Context, coupons []minicommerce.Coupon) error {
+	for start := 0; start < len(coupons); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(coupons) {
+			end = len(coupons)
+		}
+
+		batch := c.client.Batch()
+		for _, coupon := range coupons[start:end] {
+			batch.Create(c.client.Collection(couponsCollection).Doc(coupon.ID), coupon)
+		}
+
+		if _, err := batch.Commit(ctx); err != nil {
+			// should deleting fail as well, the coupons that are left can still be found by their batch
+			c.deleteAll(ctx, coupons[:start])
+
+			if isAlreadyExists(err) {
+				return &DocumentExistsError{fmt.Sprintf("%s?batch=%s", couponsCollection, coupons[start].Batch)}
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteAll deletes the coupons in batched writes of at most 500 coupons
+func (c *CouponsRepository) deleteAll(ctx context.Context, coupons []minicommerce.Coupon) error {
+	for start := 0; start < len(coupons); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(coupons) {
+			end = len(coupons)
+		}
+
+		batch := c.client.Batch()
+		for _, coupon := range coupons[start:end] {
+			batch.Delete(c.client.Collection(couponsCollection).Doc(coupon.ID))
+		}
+
+		if _, err := batch.Commit(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// couponFields are the fields Update writes, the redemptions are only ever changed by Redeem and Release
+var couponFields = []firestore.FieldPath{
+	{"description"},
+	{"active"},
+	{"amountOff"},
+	{"percentOff"},
+	{"maxRedemptions"},
+	{"redeemBy"},
+	{"redeemBefore"},
+	{"productIds"},
+	{"productTypes"},
+	{"minimumAmount"},
+	{"firstOrderOnly"},
+}
+
 // Update ...
 func (c *CouponsRepository) Update(ctx context.Context, coupon minicommerce.Coupon) error {
 	docRef := c.client.Collection(couponsCollection).Doc(coupon.ID)
-	_, err := docRef.Set(ctx, coupon)
+	_, err := docRef.Set(ctx, coupon, firestore.Merge(couponFields...))
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Redeem increments the redemptions of the coupon inside a transaction, so concurrent redemptions
+// can't exceed MaxRedemptions. A coupon that is exhausted returns a CouponExhaustedError
+func (c *CouponsRepository) Redeem(ctx context.Context, code string) error {
+	docRef := c.client.Collection(couponsCollection).Doc(code)
+
+	return c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		coupon, err := getCouponInTransaction(tx, docRef)
+		if err != nil {
+			return err
+		}
+
+		return redeemInTransaction(tx, docRef, coupon)
+	})
+}
+
+// Release gives a redemption of the coupon back, the redemptions never go below zero
+func (c *CouponsRepository) Release(ctx context.Context, code string) error {
+	docRef := c.client.Collection(couponsCollection).Doc(code)
+
+	return c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		coupon, err := getCouponInTransaction(tx, docRef)
+		if err != nil {
+			return err
+		}
+
+		return releaseInTransaction(tx, docRef, coupon)
+	})
+}
+
+// redeemInTransaction increments the redemptions of the coupon that was read in the transaction
+func redeemInTransaction(tx *firestore.Transaction, docRef *firestore.DocumentRef, coupon *minicommerce.Coupon) error {
+	if coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions {
+		return &minicommerce.CouponExhaustedError{Code: docRef.ID}
+	}
+
+	return tx.Update(docRef, []firestore.Update{{Path: "redemptions", Value: coupon.Redemptions + 1}})
+}
+
+// releaseInTransaction decrements the redemptions of the coupon that was read in the transaction
+func releaseInTransaction(tx *firestore.Transaction, docRef *firestore.DocumentRef, coupon *minicommerce.Coupon) error {
+	if coupon.Redemptions == 0 {
+		return nil
+	}
+
+	return tx.Update(docRef, []firestore.Update{{Path: "redemptions", Value: coupon.Redemptions - 1}})
+}
+
+func getCouponInTransaction(tx *firestore.Transaction, docRef *firestore.DocumentRef) (*minicommerce.Coupon, error) {
+	snapshot, err := tx.Get(docRef)
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", couponsCollection, docRef.ID)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var coupon minicommerce.Coupon
+	if err := snapshot.DataTo(&coupon); err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
@@ -2,6 +2,8 @@ package firestore
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/bradleyjkemp/cupaloy/v2"
@@ -146,3 +148,216 @@ func TestUpdateCoupon(t *testing.T) {
 
 	cupaloy.SnapshotT(t, snapshot.Data())
 }
+
+func TestRedeemCoupon(t *testing.T) {
+	ctx := context.Background()
+	c := minicommerce.Coupon{
+		ID:             "redeem-code",
+		Active:         true,
+		AmountOff:      500,
+		MaxRedemptions: 5,
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer func() {
+		client.Collection(couponsCollection).Doc(c.ID).Delete(ctx)
+		client.Close()
+	}()
+
+	if _, err := client.Collection(couponsCollection).Doc(c.ID).Set(ctx, c); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewCouponsRepository(client)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- repo.Redeem(ctx, c.ID)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	redeemed, exhausted := 0, 0
+	for err := range results {
+		switch err.(type) {
+		case nil:
+			redeemed++
+		case *minicommerce.CouponExhaustedError:
+			exhausted++
+		default:
+			t.Error(err.Error())
+		}
+	}
+
+	if redeemed != 5 || exhausted != 5 {
+		t.Errorf("expected 5 redemptions and 5 rejections, got %d and %d", redeemed, exhausted)
+	}
+
+	coupon, err := repo.GetByCode(ctx, c.ID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if coupon.Redemptions != 5 {
+		t.Errorf("expected the coupon to be redeemed 5 times, got %d", coupon.Redemptions)
+	}
+}
+
+func TestReleaseCoupon(t *testing.T) {
+	ctx := context.Background()
+	c := minicommerce.Coupon{
+		ID:             "release-code",
+		Active:         true,
+		AmountOff:      500,
+		MaxRedemptions: 1,
+		Redemptions:    1,
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer func() {
+		client.Collection(couponsCollection).Doc(c.ID).Delete(ctx)
+		client.Close()
+	}()
+
+	if _, err := client.Collection(couponsCollection).Doc(c.ID).Set(ctx, c); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewCouponsRepository(client)
+
+	// releasing more than was redeemed must not take the redemptions below zero
+	for i := 0; i < 2; i++ {
+		if err := repo.Release(ctx, c.ID); err != nil {
+			t.Error(err.Error())
+		}
+	}
+
+	if err := repo.Redeem(ctx, c.ID); err != nil {
+		t.Errorf("expected the released redemption to be available again, got %v", err)
+	}
+
+	coupon, err := repo.GetByCode(ctx, c.ID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if coupon.Redemptions != 1 {
+		t.Errorf("expected the coupon to be redeemed once, got %d", coupon.Redemptions)
+	}
+}
+
+func TestCreateAllCoupons(t *testing.T) {
+	ctx := context.Background()
+
+	// more coupons than fit in a single batch
+	var cc []minicommerce.Coupon
+	for i := 0; i < maxBatchSize+10; i++ {
+		cc = append(cc, minicommerce.Coupon{
+			ID:             fmt.Sprintf("create-all-%d", i),
+			Batch:          "create-all",
+			Active:         true,
+			AmountOff:      500,
+			MaxRedemptions: 1,
+		})
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer func() {
+		for _, c := range cc {
+			client.Collection(couponsCollection).Doc(c.ID).Delete(ctx)
+		}
+		client.Close()
+	}()
+
+	repo := NewCouponsRepository(client)
+	if err := repo.CreateAll(ctx, cc); err != nil {
+		t.Error(err.Error())
+	}
+
+	for _, c := range []minicommerce.Coupon{cc[0], cc[len(cc)-1]} {
+		coupon, err := repo.GetByCode(ctx, c.ID)
+		if err != nil {
+			t.Error(err.Error())
+			continue
+		}
+
+		if coupon.MaxRedemptions != 1 {
+			t.Errorf("expected the coupon %s to be created, got %+v", c.ID, coupon)
+		}
+	}
+
+	batch, err := repo.GetByBatch(ctx, "create-all")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if len(batch) != len(cc) {
+		t.Errorf("expected %d coupons in the batch, got %d", len(cc), len(batch))
+	}
+}
+
+func TestCreateAllCouponsRollsBack(t *testing.T) {
+	ctx := context.Background()
+
+	// the second batch fails on a code that already exists
+	var cc []minicommerce.Coupon
+	for i := 0; i < maxBatchSize+1; i++ {
+		cc = append(cc, minicommerce.Coupon{
+			ID:             fmt.Sprintf("create-all-rollback-%d", i),
+			Batch:          "create-all-rollback",
+			Active:         true,
+			AmountOff:      500,
+			MaxRedemptions: 1,
+		})
+	}
+	existing := cc[len(cc)-1]
+	existing.Batch = ""
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer func() {
+		for _, c := range cc {
+			client.Collection(couponsCollection).Doc(c.ID).Delete(ctx)
+		}
+		client.Close()
+	}()
+
+	repo := NewCouponsRepository(client)
+	if err := repo.Create(ctx, existing); err != nil {
+		t.Error(err.Error())
+	}
+
+	err = repo.CreateAll(ctx, cc)
+	if _, ok := err.(*DocumentExistsError); !ok {
+		t.Errorf("expected DocumentExistsError, got %v", err)
+	}
+
+	batch, err := repo.GetByBatch(ctx, "create-all-rollback")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if len(batch) != 0 {
+		t.Errorf("expected the first batch to be deleted again, got %d coupons", len(batch))
+	}
+}
@@ -0,0 +1,59 @@
+package firestore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"cloud.google.com/go/firestore"
+	"github.com/eikc/minicommerce"
+)
+
+// defaultLimit is the page size used when the list options don't set a limit
+const defaultLimit = 20
+
+// cursor holds the sort values of the last document on a page, the next page starts after it.
+// Only the values of the field a collection is sorted by are set, the ID breaks ties between equal values
+type cursor struct {
+	Created int64  `json:"created,omitempty"`
+	Name    string `json:"name,omitempty"`
+	ID      string `json:"id"`
+}
+
+// encode turns the cursor into the opaque token handed out to clients
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses a token made by encode
+func decodeCursor(token string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, &InvalidCursorError{token}
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, &InvalidCursorError{token}
+	}
+
+	return &c, nil
+}
+
+// direction maps the sort order to the firestore direction, lists are ascending unless asked otherwise
+func direction(order minicommerce.SortOrder) firestore.Direction {
+	if order == minicommerce.SortDescending {
+		return firestore.Desc
+	}
+
+	return firestore.Asc
+}
+
+// limit is the page size of the list options
+func limit(opts minicommerce.ListOptions) int {
+	if opts.Limit <= 0 {
+		return defaultLimit
+	}
+
+	return opts.Limit
+}
@@ -29,6 +29,10 @@ func (d *DownloadableService) GenerateID() string {
 func (d *DownloadableService) Get(ctx context.Context, id string) (*minicommerce.Downloadable, error) {
 	docRef := d.client.Collection(downloadableCollection).Doc(id)
 	snapshot, err := docRef.Get(ctx)
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", downloadableCollection, id)}
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +52,29 @@ func (d *DownloadableService) Get(ctx context.Context, id string) (*minicommerce
 	return &downloadable, nil
 }
 
+// GetByHash will return a downloadable with the given hash of its file, any of them when more than one has it
+func (d *DownloadableService) GetByHash(ctx context.Context, hash string) (*minicommerce.Downloadable, error) {
+	query := d.client.Collection(downloadableCollection).Where("hash", "==", hash).Limit(1)
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(docs) == 0 {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s?hash=%s", downloadableCollection, hash)}
+	}
+
+	downloadable := minicommerce.Downloadable{
+		ID: docs[0].Ref.ID,
+	}
+
+	if err := docs[0].DataTo(&downloadable); err != nil {
+		return nil, err
+	}
+
+	return &downloadable, nil
+}
+
 // GetAll will get all non deleted downloadables from firestore
 func (d *DownloadableService) GetAll(ctx context.Context) ([]minicommerce.Downloadable, error) {
 	colRef := d.client.Collection(downloadableCollection)
@@ -68,6 +95,49 @@ func (d *DownloadableService) GetAll(ctx context.Context) ([]minicommerce.Downlo
 	return collection, nil
 }
 
+// List returns a single page of the downloadables sorted by name
+func (d *DownloadableService) List(ctx context.Context, opts minicommerce.ListOptions) (*minicommerce.DownloadablePage, error) {
+	dir := direction(opts.Order)
+	query := d.client.Collection(downloadableCollection).OrderBy("name", dir).OrderBy(firestore.DocumentID, dir)
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.StartAfter(c.Name, c.ID)
+	}
+
+	// one more document than the limit is read to know if there is a next page
+	n := limit(opts)
+	docs, err := query.Limit(n + 1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &minicommerce.DownloadablePage{
+		Downloadables: make([]minicommerce.Downloadable, 0, n),
+	}
+	for i, doc := range docs {
+		if i == n {
+			last := page.Downloadables[n-1]
+			page.NextCursor = cursor{Name: last.Name, ID: last.ID}.encode()
+			break
+		}
+
+		downloadable := minicommerce.Downloadable{
+			ID: doc.Ref.ID,
+		}
+		if err := doc.DataTo(&downloadable); err != nil {
+			return nil, err
+		}
+
+		page.Downloadables = append(page.Downloadables, downloadable)
+	}
+
+	return page, nil
+}
+
 // Create will create a documents in firestore with the given data, if the document ID exist it will fail
 func (d *DownloadableService) Create(ctx context.Context, downloadable *minicommerce.Downloadable) error {
 	docRef := d.client.Collection(downloadableCollection).Doc(downloadable.ID)
@@ -80,13 +150,114 @@ func (d *DownloadableService) Create(ctx context.Context, downloadable *minicomm
 	return nil
 }
 
+// CreateSharingFile creates the document of the downloadable in a transaction with looking up a downloadable with
+// the same hash, whose location it takes over. A downloadable that is deleted at the same time makes the transaction
+// retry, so it never ends up pointing at a file that is being deleted. A downloadable without a hash shares nothing
+func (d *DownloadableService) CreateSharingFile(ctx context.Context, downloadable *minicommerce.Downloadable) error {
+	colRef := d.client.Collection(downloadableCollection)
+	docRef := colRef.Doc(downloadable.ID)
+
+	var location string
+	err := d.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		location = downloadable.Location
+		if downloadable.Hash != "" {
+			docs, err := tx.Documents(colRef.Where("hash", "==", downloadable.Hash).Limit(1)).GetAll()
+			if err != nil {
+				return err
+			}
+
+			if len(docs) > 0 {
+				var existing minicommerce.Downloadable
+				if err := docs[0].DataTo(&existing); err != nil {
+					return err
+				}
+				location = existing.Location
+			}
+		}
+
+		shared := *downloadable
+		shared.Location = location
+		return tx.Create(docRef, &shared)
+	})
+	if err != nil {
+		return err
+	}
+
+	downloadable.Location = location
+	return nil
+}
+
+// Update will overwrite the document of the downloadable with the given data
+func (d *DownloadableService) Update(ctx context.Context, downloadable *minicommerce.Downloadable) error {
+	docRef := d.client.Collection(downloadableCollection).Doc(downloadable.ID)
+	if _, err := docRef.Set(ctx, downloadable); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LocationReferenced reports whether any downloadable is stored at the location. Downloadables with the same file
+// share a single location, so it tells whether the file is still needed
+func (d *DownloadableService) LocationReferenced(ctx context.Context, location string) (bool, error) {
+	query := d.client.Collection(downloadableCollection).Where("location", "==", location).Limit(1)
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return false, err
+	}
+
+	return len(docs) > 0, nil
+}
+
 // Delete will remove a document from the firestore collection
 func (d *DownloadableService) Delete(ctx context.Context, id string) error {
 	docRef := d.client.Collection(downloadableCollection).Doc(id)
-	_, err := docRef.Collection(downloadableCollection).Doc(id).Delete(ctx)
-	if err != nil {
+	if _, err := docRef.Delete(ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// DeleteReleasingFile deletes the document of the downloadable in a transaction with looking up the other
+// downloadables stored at its location, so an upload can't start sharing the file before the caller deletes it
+func (d *DownloadableService) DeleteReleasingFile(ctx context.Context, id string) (bool, error) {
+	colRef := d.client.Collection(downloadableCollection)
+	docRef := colRef.Doc(id)
+
+	var released bool
+	err := d.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snapshot, err := tx.Get(docRef)
+		if isNotFound(err) {
+			return &DocumentNotFoundError{fmt.Sprintf("%s/%s", downloadableCollection, id)}
+		}
+		if err != nil {
+			return err
+		}
+
+		var downloadable minicommerce.Downloadable
+		if err := snapshot.DataTo(&downloadable); err != nil {
+			return err
+		}
+
+		// the downloadable itself is one of them, so two are enough to know whether another one shares the file
+		docs, err := tx.Documents(colRef.Where("location", "==", downloadable.Location).Limit(2)).GetAll()
+		if err != nil {
+			return err
+		}
+
+		released = true
+		for _, doc := range docs {
+			if doc.Ref.ID != id {
+				released = false
+			}
+		}
+
+		return tx.Delete(docRef)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return released, nil
+}
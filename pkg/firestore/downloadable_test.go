@@ -2,6 +2,7 @@ package firestore
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/bradleyjkemp/cupaloy/v2"
@@ -42,6 +43,47 @@ func TestDownloadableServiceGet(t *testing.T) {
 	cupaloy.SnapshotT(t, downloadable)
 }
 
+func TestDownloadableServiceGetByHash(t *testing.T) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	document := minicommerce.Downloadable{
+		ID:          "testing-get-by-hash-1",
+		Name:        "ebook.pdf",
+		Location:    "downloadables/testing-get-by-hash-1/ebook.pdf",
+		Hash:        "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Size:        4,
+		ContentType: "application/pdf",
+	}
+
+	defer func() {
+		client.Collection(downloadableCollection).Doc(document.ID).Delete(ctx)
+		client.Close()
+	}()
+
+	if _, err := client.Collection(downloadableCollection).Doc(document.ID).Set(ctx, document); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	downloadableService := NewDownloadableService(client)
+
+	downloadable, err := downloadableService.GetByHash(ctx, document.Hash)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !reflect.DeepEqual(*downloadable, document) {
+		t.Errorf("expected the downloadable to be %v, got %v", document, *downloadable)
+	}
+
+	if _, err := downloadableService.GetByHash(ctx, "unknown"); !isDocumentNotFound(err) {
+		t.Errorf("expected a DocumentNotFoundError for an unknown hash, got %v", err)
+	}
+}
+
 func TestDownloadableServiceCreate(t *testing.T) {
 	ctx := context.Background()
 
@@ -70,6 +112,46 @@ func TestDownloadableServiceCreate(t *testing.T) {
 	}
 }
 
+func TestDownloadableServiceUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ID := "testing-update-1"
+	defer func() {
+		client.Collection(downloadableCollection).Doc(ID).Delete(ctx)
+		client.Close()
+	}()
+
+	downloadableService := NewDownloadableService(client)
+
+	doc := minicommerce.Downloadable{
+		ID:       ID,
+		Name:     "ebook.pdf",
+		Location: "ebook.pdf",
+	}
+	if err := downloadableService.Create(ctx, &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	doc.Location = minicommerce.DownloadableLocation(ID, doc.Name)
+	if err := downloadableService.Update(ctx, &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	downloadable, err := downloadableService.Get(ctx, ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !reflect.DeepEqual(*downloadable, doc) {
+		t.Errorf("expected the downloadable to be %v, got %v", doc, *downloadable)
+	}
+}
+
 func TestDownloadableServiceDelete(t *testing.T) {
 	ctx := context.Background()
 
@@ -100,6 +182,104 @@ func TestDownloadableServiceDelete(t *testing.T) {
 	if err = downloadableService.Delete(ctx, doc.ID); err != nil {
 		t.Errorf(err.Error())
 	}
+
+	if _, err := downloadableService.Get(ctx, doc.ID); !isDocumentNotFound(err) {
+		t.Errorf("expected the downloadable to be deleted, got %v", err)
+	}
+}
+
+func TestDownloadableServiceLocationReferenced(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data := minicommerce.Downloadable{
+		ID:       "testing-location-referenced",
+		Name:     "ebook.pdf",
+		Location: "downloadables/testing-location-referenced/ebook.pdf",
+	}
+
+	defer func() {
+		client.Collection(downloadableCollection).Doc(data.ID).Delete(ctx)
+		client.Close()
+	}()
+
+	if _, err := client.Collection(downloadableCollection).Doc(data.ID).Set(ctx, data); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	downloadableService := NewDownloadableService(client)
+
+	referenced, err := downloadableService.LocationReferenced(ctx, data.Location)
+	if err != nil || !referenced {
+		t.Errorf("expected the location to be referenced, got %v and %v", referenced, err)
+	}
+
+	referenced, err = downloadableService.LocationReferenced(ctx, "downloadables/unknown/ebook.pdf")
+	if err != nil || referenced {
+		t.Errorf("expected the location not to be referenced, got %v and %v", referenced, err)
+	}
+}
+
+func TestDownloadableServiceSharingFile(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	original := minicommerce.Downloadable{
+		ID:       "testing-sharing-original",
+		Name:     "ebook.pdf",
+		Location: "downloadables/testing-sharing-original/ebook.pdf",
+		Hash:     "testing-sharing-hash",
+	}
+	duplicate := minicommerce.Downloadable{
+		ID:       "testing-sharing-duplicate",
+		Name:     "copy.pdf",
+		Location: "downloadables/testing-sharing-duplicate/copy.pdf",
+		Hash:     "testing-sharing-hash",
+	}
+
+	defer func() {
+		client.Collection(downloadableCollection).Doc(original.ID).Delete(ctx)
+		client.Collection(downloadableCollection).Doc(duplicate.ID).Delete(ctx)
+		client.Close()
+	}()
+
+	downloadableService := NewDownloadableService(client)
+
+	if err := downloadableService.CreateSharingFile(ctx, &original); err != nil {
+		t.Fatal(err.Error())
+	}
+	if original.Location != "downloadables/testing-sharing-original/ebook.pdf" {
+		t.Errorf("expected the first downloadable to keep its location, got %s", original.Location)
+	}
+
+	if err := downloadableService.CreateSharingFile(ctx, &duplicate); err != nil {
+		t.Fatal(err.Error())
+	}
+	if duplicate.Location != original.Location {
+		t.Errorf("expected the duplicate to share the location %s, got %s", original.Location, duplicate.Location)
+	}
+
+	released, err := downloadableService.DeleteReleasingFile(ctx, original.ID)
+	if err != nil || released {
+		t.Errorf("expected the file to still be shared, got %v and %v", released, err)
+	}
+
+	released, err = downloadableService.DeleteReleasingFile(ctx, duplicate.ID)
+	if err != nil || !released {
+		t.Errorf("expected the file to be released by the last downloadable, got %v and %v", released, err)
+	}
+
+	if _, err := downloadableService.DeleteReleasingFile(ctx, duplicate.ID); !isDocumentNotFound(err) {
+		t.Errorf("expected DocumentNotFoundError, got %v", err)
+	}
 }
 
 func TestGetAllDownloadable(t *testing.T) {
@@ -135,3 +315,56 @@ func TestGetAllDownloadable(t *testing.T) {
 
 	cupaloy.SnapshotT(t, downloadables)
 }
+
+func TestListDownloadables(t *testing.T) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dd := []minicommerce.Downloadable{
+		{ID: "list-1", Name: "list b", Location: "b.pdf"},
+		{ID: "list-2", Name: "list a", Location: "a.pdf"},
+		{ID: "list-3", Name: "list c", Location: "c.pdf"},
+	}
+
+	defer func() {
+		for _, d := range dd {
+			client.Collection(downloadableCollection).Doc(d.ID).Delete(ctx)
+		}
+		client.Close()
+	}()
+
+	for _, d := range dd {
+		if _, err := client.Collection(downloadableCollection).Doc(d.ID).Set(ctx, d); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	service := NewDownloadableService(client)
+
+	first, err := service.List(ctx, minicommerce.ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	second, err := service.List(ctx, minicommerce.ListOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []minicommerce.Downloadable{dd[1], dd[0]}
+	if !reflect.DeepEqual(first.Downloadables, expected) || first.NextCursor == "" {
+		t.Errorf("expected the first page to be %v with a cursor, got %v", expected, first)
+	}
+
+	expected = []minicommerce.Downloadable{dd[2]}
+	if !reflect.DeepEqual(second.Downloadables, expected) || second.NextCursor != "" {
+		t.Errorf("expected the last page to be %v without a cursor, got %v", expected, second)
+	}
+}
+
+func isDocumentNotFound(err error) bool {
+	_, ok := err.(*DocumentNotFoundError)
+	return ok
+}
@@ -0,0 +1,115 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/eikc/minicommerce"
+)
+
+const downloadCountsCollection string = "downloadCounts"
+const downloadAccessCollection string = "downloadAccess"
+const downloadLinksCollection string = "downloadLinks"
+
+// DownloadsRepository keeps the download counters and the access log of the downloads in firestore
+type DownloadsRepository struct {
+	client *firestore.Client
+}
+
+// NewDownloadsRepository constructs the downloads repository
+func NewDownloadsRepository(c *firestore.Client) *DownloadsRepository {
+	return &DownloadsRepository{c}
+}
+
+// countedLink records that a signed link has been counted as a download for the client with the IP. It is only needed
+// until the link expires, a TTL policy on expires should be set on the collection so firestore deletes it after that
+type countedLink struct {
+	OrderID        string    `firestore:"orderId"`
+	DownloadableID string    `firestore:"downloadableId"`
+	IP             string    `firestore:"ip"`
+	Counted        int64     `firestore:"counted"`
+	Expires        time.Time `firestore:"expires"`
+}
+
+// Count increments the counter of the downloadable of the order inside a transaction,
+// so concurrent downloads can't exceed the limits. The first download starts the window.
+// The links that have been counted are kept in the same transaction, so a download resumed
+// through a link by the same client is only counted once
+func (d *DownloadsRepository) Count(ctx context.Context, link minicommerce.DownloadLink, access *minicommerce.DownloadAccess, limits minicommerce.DownloadLimits) error {
+	orderID, downloadableID, now := access.OrderID, access.DownloadableID, access.Time
+	docRef := d.client.Collection(downloadCountsCollection).Doc(fmt.Sprintf("%s_%s", orderID, downloadableID))
+	linkRef := d.client.Collection(downloadLinksCollection).Doc(link.Signature)
+
+	return d.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		count := minicommerce.DownloadCount{
+			OrderID:        orderID,
+			DownloadableID: downloadableID,
+			First:          now,
+		}
+
+		snapshot, err := tx.Get(docRef)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+
+		if err == nil {
+			if err := snapshot.DataTo(&count); err != nil {
+				return err
+			}
+		}
+
+		var counted countedLink
+		snapshot, err = tx.Get(linkRef)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+
+		resumed := false
+		if err == nil {
+			if err := snapshot.DataTo(&counted); err != nil {
+				return err
+			}
+
+			resumed = access.Offset > 0 && counted.IP == access.IP
+		}
+
+		if !resumed && limits.MaxDownloads > 0 && count.Downloads >= limits.MaxDownloads {
+			return &minicommerce.DownloadLimitError{OrderID: orderID, DownloadableID: downloadableID}
+		}
+
+		if limits.Window > 0 && now >= count.First+limits.Window {
+			return &minicommerce.DownloadLimitError{OrderID: orderID, DownloadableID: downloadableID, Expired: true}
+		}
+
+		if resumed {
+			return nil
+		}
+
+		count.Downloads++
+		if err := tx.Set(docRef, count); err != nil {
+			return err
+		}
+
+		return tx.Set(linkRef, countedLink{
+			OrderID:        orderID,
+			DownloadableID: downloadableID,
+			IP:             access.IP,
+			Counted:        now,
+			Expires:        time.Unix(link.Expires, 0),
+		})
+	})
+}
+
+// Log adds the access to the log, the ID of the access is generated by firestore
+func (d *DownloadsRepository) Log(ctx context.Context, access *minicommerce.DownloadAccess) error {
+	docRef := d.client.Collection(downloadAccessCollection).NewDoc()
+	if _, err := docRef.Create(ctx, access); err != nil {
+		return err
+	}
+
+	access.ID = docRef.ID
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/eikc/minicommerce"
+)
+
+// countAccess is a request from the same client for the downloadable of the order at the unix time now
+func countAccess(orderID, downloadableID string, offset, now int64) *minicommerce.DownloadAccess {
+	return &minicommerce.DownloadAccess{OrderID: orderID, DownloadableID: downloadableID, Time: now, Offset: offset, IP: "203.0.113.7"}
+}
+
+func TestCountDownloads(t *testing.T) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		client.Collection(downloadCountsCollection).Doc("count-order_count-file").Delete(ctx)
+		for i := 0; i < 6; i++ {
+			client.Collection(downloadLinksCollection).Doc(fmt.Sprintf("count-link-%d", i)).Delete(ctx)
+		}
+		client.Close()
+	}()
+
+	repo := NewDownloadsRepository(client)
+	limits := minicommerce.DownloadLimits{MaxDownloads: 3}
+
+	var wg sync.WaitGroup
+	results := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(link string) {
+			defer wg.Done()
+			results <- repo.Count(ctx, minicommerce.DownloadLink{Signature: link, Expires: 4600}, countAccess("count-order", "count-file", 0, 1000), limits)
+		}(fmt.Sprintf("count-link-%d", i))
+	}
+	wg.Wait()
+	close(results)
+
+	counted, limited := 0, 0
+	for err := range results {
+		switch err.(type) {
+		case nil:
+			counted++
+		case *minicommerce.DownloadLimitError:
+			limited++
+		default:
+			t.Error(err.Error())
+		}
+	}
+
+	if counted != 3 || limited != 3 {
+		t.Errorf("expected 3 downloads and 3 rejections, got %d and %d", counted, limited)
+	}
+}
+
+func TestCountDownloadsWindow(t *testing.T) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		client.Collection(downloadCountsCollection).Doc("window-order_window-file").Delete(ctx)
+		for _, link := range []string{"window-link-1", "window-link-2", "window-link-3"} {
+			client.Collection(downloadLinksCollection).Doc(link).Delete(ctx)
+		}
+		client.Close()
+	}()
+
+	repo := NewDownloadsRepository(client)
+	limits := minicommerce.DownloadLimits{Window: 100}
+
+	link := func(signature string) minicommerce.DownloadLink {
+		return minicommerce.DownloadLink{Signature: signature, Expires: 4600}
+	}
+
+	if err := repo.Count(ctx, link("window-link-1"), countAccess("window-order", "window-file", 0, 1000), limits); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := repo.Count(ctx, link("window-link-2"), countAccess("window-order", "window-file", 0, 1099), limits); err != nil {
+		t.Errorf("expected a download inside the window to be counted, got %v", err)
+	}
+
+	err = repo.Count(ctx, link("window-link-3"), countAccess("window-order", "window-file", 0, 1100), limits)
+	if limitErr, ok := err.(*minicommerce.DownloadLimitError); !ok || !limitErr.Expired {
+		t.Errorf("expected the window to have passed, got %v", err)
+	}
+
+	// a counted link can't be used to resume the download once the window has passed either
+	err = repo.Count(ctx, link("window-link-2"), countAccess("window-order", "window-file", 4, 1100), limits)
+	if limitErr, ok := err.(*minicommerce.DownloadLimitError); !ok || !limitErr.Expired {
+		t.Errorf("expected the window to have passed, got %v", err)
+	}
+}
+
+func TestCountDownloadsResumed(t *testing.T) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		client.Collection(downloadCountsCollection).Doc("link-order_link-file").Delete(ctx)
+		client.Collection(downloadLinksCollection).Doc("link-resumed").Delete(ctx)
+		client.Close()
+	}()
+
+	repo := NewDownloadsRepository(client)
+	limits := minicommerce.DownloadLimits{MaxDownloads: 1}
+	link := minicommerce.DownloadLink{Signature: "link-resumed", Expires: 4600}
+
+	if err := repo.Count(ctx, link, countAccess("link-order", "link-file", 0, 1000), limits); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := repo.Count(ctx, link, countAccess("link-order", "link-file", 4, 1000), limits); err != nil {
+			t.Fatalf("expected the download to be resumed through the same link, got %v", err)
+		}
+	}
+
+	err = repo.Count(ctx, link, countAccess("link-order", "link-file", 0, 1000), limits)
+	if _, ok := err.(*minicommerce.DownloadLimitError); !ok {
+		t.Errorf("expected a new download through the link to be counted as another download, got %v", err)
+	}
+
+	shared := countAccess("link-order", "link-file", 4, 1000)
+	shared.IP = "198.51.100.23"
+	err = repo.Count(ctx, link, shared, limits)
+	if _, ok := err.(*minicommerce.DownloadLimitError); !ok {
+		t.Errorf("expected a resume from another client to be counted as another download, got %v", err)
+	}
+}
+
+func TestLogDownloadAccess(t *testing.T) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewDownloadsRepository(client)
+	access := minicommerce.DownloadAccess{
+		OrderID:        "log-order",
+		DownloadableID: "log-file",
+		Time:           1000,
+		Offset:         4,
+		IP:             "203.0.113.7",
+		UserAgent:      "curl/7.64.1",
+	}
+
+	if err := repo.Log(ctx, &access); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		client.Collection(downloadAccessCollection).Doc(access.ID).Delete(ctx)
+		client.Close()
+	}()
+
+	snapshot, err := client.Collection(downloadAccessCollection).Doc(access.ID).Get(ctx)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var stored minicommerce.DownloadAccess
+	if err := snapshot.DataTo(&stored); err != nil {
+		t.Fatal(err.Error())
+	}
+	stored.ID = snapshot.Ref.ID
+
+	if stored != access {
+		t.Errorf("expected the stored access to be %v, got %v", access, stored)
+	}
+}
@@ -2,6 +2,9 @@ package firestore
 
 import (
 	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // DocumentNotFoundError is the error returned when a document is not found
@@ -12,3 +15,31 @@ type DocumentNotFoundError struct {
 func (e *DocumentNotFoundError) Error() string {
 	return fmt.Sprintf("The document at path: %s does not exist", e.path)
 }
+
+// DocumentExistsError is the error returned when a document is created but already exists
+type DocumentExistsError struct {
+	path string
+}
+
+func (e *DocumentExistsError) Error() string {
+	return fmt.Sprintf("The document at path: %s already exists", e.path)
+}
+
+// isNotFound reports whether err is the NotFound error firestore returns for missing documents
+func isNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
+
+// isAlreadyExists reports whether err is the AlreadyExists error firestore returns when creating an existing document
+func isAlreadyExists(err error) bool {
+	return status.Code(err) == codes.AlreadyExists
+}
+
+// InvalidCursorError is returned when a list is continued from a cursor that was not handed out by the repository
+type InvalidCursorError struct {
+	cursor string
+}
+
+func (e *InvalidCursorError) Error() string {
+	return fmt.Sprintf("The cursor: %s is not valid", e.cursor)
+}
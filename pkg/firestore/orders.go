@@ -50,12 +50,12 @@ func (o *OrdersRepository) GetAll(ctx context.Context) ([]minicommerce.Order, er
 func (o *OrdersRepository) Get(ctx context.Context, id string) (*minicommerce.Order, error) {
 	docRef := o.client.Collection(ordersCollection).Doc(id)
 	snapshot, err := docRef.Get(ctx)
-	if err != nil {
-		return nil, err
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", ordersCollection, id)}
 	}
 
-	if !snapshot.Exists() {
-		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", ordersCollection, id)}
+	if err != nil {
+		return nil, err
 	}
 
 	order := minicommerce.Order{
@@ -69,6 +69,55 @@ func (o *OrdersRepository) Get(ctx context.Context, id string) (*minicommerce.Or
 	return &order, nil
 }
 
+// GetByCustomerEmail returns all the orders placed with the email of the customer
+func (o *OrdersRepository) GetByCustomerEmail(ctx context.Context, email string) ([]minicommerce.Order, error) {
+	query := o.client.Collection(ordersCollection).Where("customer.email", "==", email)
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []minicommerce.Order
+
+	for _, d := range docs {
+		order := minicommerce.Order{
+			ID: d.Ref.ID,
+		}
+
+		if err := d.DataTo(&order); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// ProductReferenced reports whether any order contains the product. The items are embedded in the orders,
+// which firestore can't query on, so all the orders are read and checked
+func (o *OrdersRepository) ProductReferenced(ctx context.Context, productID string) (bool, error) {
+	docs, err := o.client.Collection(ordersCollection).Select("items").Documents(ctx).GetAll()
+	if err != nil {
+		return false, err
+	}
+
+	for _, d := range docs {
+		var order minicommerce.Order
+		if err := d.DataTo(&order); err != nil {
+			return false, err
+		}
+
+		for _, item := range order.Items {
+			if item.ID == productID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 // Create ...
 func (o *OrdersRepository) Create(ctx context.Context, order *minicommerce.Order) error {
 	docRef := o.client.Collection(ordersCollection).Doc(order.ID)
@@ -79,6 +128,27 @@ func (o *OrdersRepository) Create(ctx context.Context, order *minicommerce.Order
 	return nil
 }
 
+// Edit replaces the order with the firestore set method, in a transaction that checks that the order hasn't
+// been checked out, so it can't race Place
+func (o *OrdersRepository) Edit(ctx context.Context, order *minicommerce.Order) error {
+	docRef := o.client.Collection(ordersCollection).Doc(order.ID)
+	return o.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing, err := getOrderInTransaction(tx, docRef)
+		if err != nil {
+			return err
+		}
+
+		if existing.PaymentID != "" {
+			return &minicommerce.AlreadyCheckedOutError{OrderID: order.ID}
+		}
+
+		order.PaymentID = existing.PaymentID
+		order.Refunded = existing.Refunded
+
+		return tx.Set(docRef, order)
+	})
+}
+
 // Update updates the existing orders document by replacing it using the firestore set method
 func (o *OrdersRepository) Update(ctx context.Context, order *minicommerce.Order) error {
 	docRef := o.client.Collection(ordersCollection).Doc(order.ID)
@@ -88,3 +158,168 @@ func (o *OrdersRepository) Update(ctx context.Context, order *minicommerce.Order
 
 	return nil
 }
+
+// Place attaches the payment to the order, creates the payment and redeems the coupon of the order in a transaction.
+// The order is read again inside the transaction, so only one of two concurrent checkouts of the same cart can place it
+// and concurrent checkouts can't redeem a coupon more than MaxRedemptions times
+func (o *OrdersRepository) Place(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) error {
+	orderRef := o.client.Collection(ordersCollection).Doc(order.ID)
+	paymentRef := o.client.Collection(paymentsCollection).Doc(payment.ID)
+
+	err := o.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		current, err := getOrderInTransaction(tx, orderRef)
+		if err != nil {
+			return err
+		}
+
+		if current.PaymentID != "" {
+			return &minicommerce.AlreadyCheckedOutError{OrderID: order.ID}
+		}
+
+		if order.Coupon != "" {
+			couponRef := o.client.Collection(couponsCollection).Doc(order.Coupon)
+			coupon, err := getCouponInTransaction(tx, couponRef)
+			if err != nil {
+				return err
+			}
+
+			if err := redeemInTransaction(tx, couponRef, coupon); err != nil {
+				return err
+			}
+		}
+
+		placed := *order
+		placed.PaymentID = payment.ID
+
+		if err := tx.Create(paymentRef, payment); err != nil {
+			return err
+		}
+
+		return tx.Set(orderRef, placed)
+	})
+	if err != nil {
+		return err
+	}
+
+	order.PaymentID = payment.ID
+
+	return nil
+}
+
+// Cancel takes the payment off the order, deletes the payment and gives the redemption of the coupon back
+// in a transaction. An order that has been placed with another payment in the meantime keeps that payment
+// and its redemption
+func (o *OrdersRepository) Cancel(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) error {
+	orderRef := o.client.Collection(ordersCollection).Doc(order.ID)
+	paymentRef := o.client.Collection(paymentsCollection).Doc(payment.ID)
+
+	err := o.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		current, err := getOrderInTransaction(tx, orderRef)
+		if err != nil {
+			return err
+		}
+
+		if current.PaymentID != payment.ID {
+			return tx.Delete(paymentRef)
+		}
+
+		couponRef, coupon, err := o.couponInTransaction(tx, current)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Update(orderRef, []firestore.Update{{Path: "paymentId", Value: ""}}); err != nil {
+			return err
+		}
+
+		if coupon != nil {
+			if err := releaseInTransaction(tx, couponRef, coupon); err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(paymentRef)
+	})
+	if err != nil {
+		return err
+	}
+
+	if order.PaymentID == payment.ID {
+		order.PaymentID = ""
+	}
+
+	return nil
+}
+
+// Refund marks the order as refunded and gives the redemption of its coupon back in a transaction.
+// An order that is already refunded is left as it is, so a retried or concurrent refund never releases twice
+func (o *OrdersRepository) Refund(ctx context.Context, orderID string) error {
+	orderRef := o.client.Collection(ordersCollection).Doc(orderID)
+
+	return o.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		order, err := getOrderInTransaction(tx, orderRef)
+		if err != nil {
+			return err
+		}
+
+		if order.Refunded {
+			return nil
+		}
+
+		couponRef, coupon, err := o.couponInTransaction(tx, order)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Update(orderRef, []firestore.Update{{Path: "refunded", Value: true}}); err != nil {
+			return err
+		}
+
+		if coupon == nil {
+			return nil
+		}
+
+		return releaseInTransaction(tx, couponRef, coupon)
+	})
+}
+
+// couponInTransaction reads the coupon of the order in the transaction,
+// the coupon is nil when the order has none or it has been deleted since
+func (o *OrdersRepository) couponInTransaction(tx *firestore.Transaction, order *minicommerce.Order) (*firestore.DocumentRef, *minicommerce.Coupon, error) {
+	if order.Coupon == "" {
+		return nil, nil, nil
+	}
+
+	docRef := o.client.Collection(couponsCollection).Doc(order.Coupon)
+	coupon, err := getCouponInTransaction(tx, docRef)
+	if _, ok := err.(*DocumentNotFoundError); ok {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return docRef, coupon, nil
+}
+
+func getOrderInTransaction(tx *firestore.Transaction, docRef *firestore.DocumentRef) (*minicommerce.Order, error) {
+	snapshot, err := tx.Get(docRef)
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", ordersCollection, docRef.ID)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	order := minicommerce.Order{
+		ID: docRef.ID,
+	}
+
+	if err := snapshot.DataTo(&order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
@@ -2,6 +2,8 @@ package firestore
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/bradleyjkemp/cupaloy/v2"
@@ -189,3 +191,323 @@ func TestUpdateOrder(t *testing.T) {
 
 	cupaloy.SnapshotT(t, snapshot.Data())
 }
+
+func TestEditOrderCheckedOut(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-order-edit-checked-out"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer cleanup(c, ordersCollection, ID)
+
+	placed := minicommerce.Order{ID: ID, Total: 15000, PaymentID: "payment-one"}
+	if _, err := c.Collection(ordersCollection).Doc(ID).Create(ctx, placed); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewOrdersRepository(c)
+	edited := minicommerce.Order{ID: ID, Total: 1}
+	if _, ok := repo.Edit(ctx, &edited).(*minicommerce.AlreadyCheckedOutError); !ok {
+		t.Error("expected the checked out order to be rejected")
+	}
+
+	order, err := repo.Get(ctx, ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if order.Total != 15000 || order.PaymentID != "payment-one" {
+		t.Errorf("expected the checked out order to be kept, got %+v", order)
+	}
+}
+
+func TestGetOrdersByCustomerEmail(t *testing.T) {
+	ctx := context.Background()
+	oo := []minicommerce.Order{
+		{
+			ID:        "customer-orders-1",
+			PaymentID: "payment-one",
+			Customer:  minicommerce.Customer{Email: "customer-orders@example.com"},
+		},
+		{
+			ID:       "customer-orders-2",
+			Customer: minicommerce.Customer{Email: "customer-orders@example.com"},
+		},
+		{
+			ID:       "customer-orders-3",
+			Customer: minicommerce.Customer{Email: "someone-else@example.com"},
+		},
+	}
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer func() {
+		for _, o := range oo {
+			c.Collection(ordersCollection).Doc(o.ID).Delete(ctx)
+		}
+		c.Close()
+	}()
+
+	for _, o := range oo {
+		doc := c.Collection(ordersCollection).Doc(o.ID)
+		if _, err := doc.Set(ctx, o); err != nil {
+			t.Error(err.Error())
+		}
+	}
+
+	repo := NewOrdersRepository(c)
+	orders, err := repo.GetByCustomerEmail(ctx, "customer-orders@example.com")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("expected the 2 orders of the customer, got %d", len(orders))
+	}
+
+	for _, o := range orders {
+		if o.Customer.Email != "customer-orders@example.com" {
+			t.Errorf("expected only orders of the customer, got %s", o.ID)
+		}
+	}
+}
+
+func TestProductReferenced(t *testing.T) {
+	ctx := context.Background()
+	o := minicommerce.Order{
+		ID:    "product-referenced-order",
+		Items: []minicommerce.Product{{ID: "referenced-product"}},
+	}
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(c, ordersCollection, o.ID)
+
+	if _, err := c.Collection(ordersCollection).Doc(o.ID).Set(ctx, o); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewOrdersRepository(c)
+
+	referenced, err := repo.ProductReferenced(ctx, "referenced-product")
+	if err != nil || !referenced {
+		t.Errorf("expected the product to be referenced, got %v and %v", referenced, err)
+	}
+
+	referenced, err = repo.ProductReferenced(ctx, "unreferenced-product")
+	if err != nil || referenced {
+		t.Errorf("expected the product not to be referenced, got %v and %v", referenced, err)
+	}
+}
+
+func TestPlaceOrder(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-order-place"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		c.Collection(paymentsCollection).Doc("place-payment-0").Delete(ctx)
+		c.Collection(paymentsCollection).Doc("place-payment-1").Delete(ctx)
+		cleanup(c, ordersCollection, ID)
+	}()
+
+	cart := minicommerce.Order{ID: ID, Total: 15000}
+	if _, err := c.Collection(ordersCollection).Doc(ID).Create(ctx, cart); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewOrdersRepository(c)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			order := cart
+			payment := minicommerce.Payment{ID: fmt.Sprintf("place-payment-%d", i), OrderID: ID, Amount: 15000}
+			results <- repo.Place(ctx, &order, &payment)
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	placed, rejected := 0, 0
+	for err := range results {
+		switch err.(type) {
+		case nil:
+			placed++
+		case *minicommerce.AlreadyCheckedOutError:
+			rejected++
+		default:
+			t.Error(err.Error())
+		}
+	}
+
+	if placed != 1 || rejected != 1 {
+		t.Errorf("expected the order to be placed once and rejected once, got %d and %d", placed, rejected)
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-order-cancel"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer cleanup(c, ordersCollection, ID)
+
+	order := minicommerce.Order{ID: ID, Total: 15000}
+	if _, err := c.Collection(ordersCollection).Doc(ID).Create(ctx, order); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewOrdersRepository(c)
+	payment := minicommerce.Payment{ID: "cancel-payment", OrderID: ID, Amount: 15000}
+	if err := repo.Place(ctx, &order, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := repo.Cancel(ctx, &order, &payment); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	stored, err := repo.Get(ctx, ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if stored.PaymentID != "" || order.PaymentID != "" {
+		t.Errorf("expected the payment to be taken off the order, got %s", stored.PaymentID)
+	}
+
+	if _, err := NewPaymentsRepository(c).Get(ctx, payment.ID); !isDocumentNotFound(err) {
+		t.Errorf("expected the payment to be deleted, got %v", err)
+	}
+}
+
+func TestPlaceOrderRedeemsCoupon(t *testing.T) {
+	ctx := context.Background()
+	coupon := minicommerce.Coupon{ID: "place-coupon", Active: true, AmountOff: 500, MaxRedemptions: 1}
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		for i := 0; i < 2; i++ {
+			c.Collection(ordersCollection).Doc(fmt.Sprintf("place-coupon-order-%d", i)).Delete(ctx)
+			c.Collection(paymentsCollection).Doc(fmt.Sprintf("place-coupon-payment-%d", i)).Delete(ctx)
+		}
+		cleanup(c, couponsCollection, coupon.ID)
+	}()
+
+	if _, err := c.Collection(couponsCollection).Doc(coupon.ID).Set(ctx, coupon); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewOrdersRepository(c)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		order := minicommerce.Order{ID: fmt.Sprintf("place-coupon-order-%d", i), Coupon: coupon.ID, Total: 9500}
+		if _, err := c.Collection(ordersCollection).Doc(order.ID).Create(ctx, order); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		wg.Add(1)
+		go func(i int, order minicommerce.Order) {
+			defer wg.Done()
+			payment := minicommerce.Payment{ID: fmt.Sprintf("place-coupon-payment-%d", i), OrderID: order.ID, Amount: 9500}
+			results <- repo.Place(ctx, &order, &payment)
+		}(i, order)
+	}
+	wg.Wait()
+	close(results)
+
+	placed, exhausted := 0, 0
+	for err := range results {
+		switch err.(type) {
+		case nil:
+			placed++
+		case *minicommerce.CouponExhaustedError:
+			exhausted++
+		default:
+			t.Error(err.Error())
+		}
+	}
+
+	if placed != 1 || exhausted != 1 {
+		t.Errorf("expected one order to be placed with the coupon and one to be rejected, got %d and %d", placed, exhausted)
+	}
+}
+
+func TestRefundOrder(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-order-refund"
+	coupon := minicommerce.Coupon{ID: "refund-coupon", Active: true, AmountOff: 500, Redemptions: 2}
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		c.Collection(couponsCollection).Doc(coupon.ID).Delete(ctx)
+		cleanup(c, ordersCollection, ID)
+	}()
+
+	if _, err := c.Collection(couponsCollection).Doc(coupon.ID).Set(ctx, coupon); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	order := minicommerce.Order{ID: ID, PaymentID: "refund-payment", Coupon: coupon.ID, Total: 9500}
+	if _, err := c.Collection(ordersCollection).Doc(ID).Create(ctx, order); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewOrdersRepository(c)
+
+	// a retried and a concurrent delivery of the refund only give the redemption back once
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := repo.Refund(ctx, ID); err != nil {
+				t.Error(err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	refunded, err := repo.Get(ctx, ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	released, err := NewCouponsRepository(c).GetByCode(ctx, coupon.ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !refunded.Refunded || released.Redemptions != 1 {
+		t.Errorf("expected the order to be refunded and the coupon to have 1 redemption, got %v and %d", refunded.Refunded, released.Redemptions)
+	}
+}
@@ -0,0 +1,55 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/eikc/minicommerce"
+)
+
+const paymentEventsCollection string = "paymentEvents"
+
+// PaymentEventsRepository keeps track of the payment events that has been processed in firestore
+type PaymentEventsRepository struct {
+	client *firestore.Client
+}
+
+// NewPaymentEventsRepository constructs the payment events repository
+func NewPaymentEventsRepository(c *firestore.Client) *PaymentEventsRepository {
+	return &PaymentEventsRepository{c}
+}
+
+// Get ...
+func (p *PaymentEventsRepository) Get(ctx context.Context, id string) (*minicommerce.PaymentEvent, error) {
+	docRef := p.client.Collection(paymentEventsCollection).Doc(id)
+	snapshot, err := docRef.Get(ctx)
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", paymentEventsCollection, id)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	event := minicommerce.PaymentEvent{
+		ID: id,
+	}
+
+	if err := snapshot.DataTo(&event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Create stores the event, if an event with the same ID is already stored a DocumentExistsError is returned
+func (p *PaymentEventsRepository) Create(ctx context.Context, event *minicommerce.PaymentEvent) error {
+	docRef := p.client.Collection(paymentEventsCollection).Doc(event.ID)
+	_, err := docRef.Create(ctx, event)
+	if isAlreadyExists(err) {
+		return &DocumentExistsError{fmt.Sprintf("%s/%s", paymentEventsCollection, event.ID)}
+	}
+
+	return err
+}
@@ -0,0 +1,66 @@
+package firestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestGetPaymentEvent(t *testing.T) {
+	ctx := context.Background()
+	e := minicommerce.PaymentEvent{
+		ID:         "testing-getting-payment-event",
+		Type:       minicommerce.PaymentEventCaptured,
+		ExternalID: "external-payment",
+		Received:   1563198147,
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(client, paymentEventsCollection, e.ID)
+
+	if _, err := client.Collection(paymentEventsCollection).Doc(e.ID).Set(ctx, e); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewPaymentEventsRepository(client)
+	event, err := repo.Get(ctx, e.ID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, event)
+}
+
+func TestCreatePaymentEventTwice(t *testing.T) {
+	ctx := context.Background()
+	e := minicommerce.PaymentEvent{
+		ID:         "testing-payment-event-create",
+		Type:       minicommerce.PaymentEventRefunded,
+		ExternalID: "external-payment",
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(client, paymentEventsCollection, e.ID)
+
+	repo := NewPaymentEventsRepository(client)
+	if err := repo.Create(ctx, &e); err != nil {
+		t.Error(err.Error())
+	}
+
+	err = repo.Create(ctx, &e)
+	if _, ok := err.(*DocumentExistsError); !ok {
+		t.Errorf("expected a DocumentExistsError, got %v", err)
+	}
+}
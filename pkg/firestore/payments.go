@@ -0,0 +1,140 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/eikc/minicommerce"
+)
+
+const paymentsCollection string = "payments"
+
+// PaymentsRepository is the repository that communicates with the firestore database when handling payments
+type PaymentsRepository struct {
+	client *firestore.Client
+}
+
+// NewPaymentsRepository constructs the payments repository
+func NewPaymentsRepository(c *firestore.Client) *PaymentsRepository {
+	return &PaymentsRepository{c}
+}
+
+// Get ...
+func (p *PaymentsRepository) Get(ctx context.Context, id string) (*minicommerce.Payment, error) {
+	docRef := p.client.Collection(paymentsCollection).Doc(id)
+	snapshot, err := docRef.Get(ctx)
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", paymentsCollection, id)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	payment := minicommerce.Payment{
+		ID: id,
+	}
+
+	if err := snapshot.DataTo(&payment); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetByExternalID returns the payment that the payment processor knows by the given external ID
+func (p *PaymentsRepository) GetByExternalID(ctx context.Context, externalID string) (*minicommerce.Payment, error) {
+	query := p.client.Collection(paymentsCollection).Where("externalID", "==", externalID).Limit(1)
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(docs) == 0 {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s?externalID=%s", paymentsCollection, externalID)}
+	}
+
+	payment := minicommerce.Payment{
+		ID: docs[0].Ref.ID,
+	}
+
+	if err := docs[0].DataTo(&payment); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// Create ...
+func (p *PaymentsRepository) Create(ctx context.Context, payment *minicommerce.Payment) error {
+	docRef := p.client.Collection(paymentsCollection).Doc(payment.ID)
+	if _, err := docRef.Create(ctx, payment); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update updates the existing payments document by replacing it using the firestore set method
+func (p *PaymentsRepository) Update(ctx context.Context, payment *minicommerce.Payment) error {
+	docRef := p.client.Collection(paymentsCollection).Doc(payment.ID)
+	if _, err := docRef.Set(ctx, payment); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCharge saves the external ID of the payment and marks it as paid once it is. Only those fields are written,
+// so a capture or refund saved by the webhook in between is kept
+func (p *PaymentsRepository) UpdateCharge(ctx context.Context, payment *minicommerce.Payment) error {
+	updates := []firestore.Update{{Path: "externalID", Value: payment.ExternalID}}
+	if payment.Paid {
+		updates = append(updates, firestore.Update{Path: "paid", Value: true})
+	}
+
+	docRef := p.client.Collection(paymentsCollection).Doc(payment.ID)
+	_, err := docRef.Update(ctx, updates)
+	if isNotFound(err) {
+		return &DocumentNotFoundError{fmt.Sprintf("%s/%s", paymentsCollection, payment.ID)}
+	}
+
+	return err
+}
+
+// Modify reads the payment with the external ID and saves the changes modify makes to it in a transaction
+func (p *PaymentsRepository) Modify(ctx context.Context, externalID string, modify func(payment *minicommerce.Payment) bool) (*minicommerce.Payment, error) {
+	query := p.client.Collection(paymentsCollection).Where("externalID", "==", externalID).Limit(1)
+
+	var payment *minicommerce.Payment
+	err := p.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+
+		if len(docs) == 0 {
+			return &DocumentNotFoundError{fmt.Sprintf("%s?externalID=%s", paymentsCollection, externalID)}
+		}
+
+		payment = &minicommerce.Payment{
+			ID: docs[0].Ref.ID,
+		}
+
+		if err := docs[0].DataTo(payment); err != nil {
+			return err
+		}
+
+		if !modify(payment) {
+			return nil
+		}
+
+		return tx.Set(docs[0].Ref, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
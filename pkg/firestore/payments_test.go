@@ -0,0 +1,230 @@
+package firestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestGetPayment(t *testing.T) {
+	ctx := context.Background()
+	p := minicommerce.Payment{
+		ID:         "testing-getting-payment",
+		OrderID:    "testing-order",
+		ExternalID: "external-payment",
+		Amount:     18750,
+		Paid:       true,
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(client, paymentsCollection, p.ID)
+
+	if _, err := client.Collection(paymentsCollection).Doc(p.ID).Set(ctx, p); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewPaymentsRepository(client)
+	payment, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, payment)
+}
+
+func TestGetPaymentNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	defer client.Close()
+
+	repo := NewPaymentsRepository(client)
+	_, err = repo.Get(ctx, "payment-does-not-exist")
+	if _, ok := err.(*DocumentNotFoundError); !ok {
+		t.Errorf("expected a DocumentNotFoundError, got %v", err)
+	}
+}
+
+func TestCreatePayment(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-payment-create"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(c, paymentsCollection, ID)
+
+	repo := NewPaymentsRepository(c)
+	payment := minicommerce.Payment{
+		ID:      ID,
+		OrderID: "testing-order",
+		Amount:  15000,
+	}
+
+	if err := repo.Create(ctx, &payment); err != nil {
+		t.Error(err.Error())
+	}
+
+	snapshot, err := c.Collection(paymentsCollection).Doc(ID).Get(ctx)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, snapshot.Data())
+}
+
+func TestUpdatePayment(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-payment-update"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(c, paymentsCollection, ID)
+
+	payment := minicommerce.Payment{
+		ID:      ID,
+		OrderID: "testing-order",
+		Amount:  15000,
+	}
+
+	if _, err := c.Collection(paymentsCollection).Doc(ID).Create(ctx, payment); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewPaymentsRepository(c)
+	payment.ExternalID = "external-payment"
+	payment.Paid = true
+
+	if err := repo.Update(ctx, &payment); err != nil {
+		t.Error(err.Error())
+	}
+
+	snapshot, err := c.Collection(paymentsCollection).Doc(ID).Get(ctx)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, snapshot.Data())
+}
+
+func TestUpdatePaymentCharge(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-payment-update-charge"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer cleanup(c, paymentsCollection, ID)
+
+	// the webhook has saved the refund before the checkout saves the charge
+	refunded := minicommerce.Payment{
+		ID:             ID,
+		OrderID:        "testing-order",
+		ExternalID:     "external-payment",
+		Amount:         15000,
+		Paid:           true,
+		Refunded:       true,
+		RefundedAmount: 15000,
+	}
+
+	if _, err := c.Collection(paymentsCollection).Doc(ID).Create(ctx, refunded); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewPaymentsRepository(c)
+	charged := minicommerce.Payment{ID: ID, OrderID: "testing-order", ExternalID: "external-payment", Amount: 15000}
+	if err := repo.UpdateCharge(ctx, &charged); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	payment, err := repo.Get(ctx, ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if *payment != refunded {
+		t.Errorf("expected the refund to be kept, got %+v", payment)
+	}
+}
+
+func TestModifyPayment(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-payment-modify"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer cleanup(c, paymentsCollection, ID)
+
+	paid := minicommerce.Payment{ID: ID, OrderID: "testing-order", ExternalID: "external-payment-modify", Amount: 15000, Paid: true}
+	if _, err := c.Collection(paymentsCollection).Doc(ID).Create(ctx, paid); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	repo := NewPaymentsRepository(c)
+	modified, err := repo.Modify(ctx, "external-payment-modify", func(payment *minicommerce.Payment) bool {
+		payment.RefundedAmount = 2500
+		return true
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	payment, err := repo.Get(ctx, ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if *payment != *modified || !payment.Paid || payment.RefundedAmount != 2500 {
+		t.Errorf("expected the refund to be saved on the paid payment, got %+v", payment)
+	}
+}
+
+func TestGetPaymentByExternalID(t *testing.T) {
+	ctx := context.Background()
+	p := minicommerce.Payment{
+		ID:         "testing-payment-by-external-id",
+		OrderID:    "testing-order",
+		ExternalID: "external-payment-lookup",
+		Amount:     18750,
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(client, paymentsCollection, p.ID)
+
+	if _, err := client.Collection(paymentsCollection).Doc(p.ID).Set(ctx, p); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewPaymentsRepository(client)
+	payment, err := repo.GetByExternalID(ctx, p.ExternalID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	cupaloy.SnapshotT(t, payment)
+}
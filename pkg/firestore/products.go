@@ -45,16 +45,67 @@ func (p *ProductRepository) GetAll(ctx context.Context) ([]minicommerce.Product,
 	return products, nil
 }
 
+// List returns a single page of the products matching the filter, sorted by the time they were created.
+// Filtering and sorting on different fields needs a composite index on the filtered fields and created
+func (p *ProductRepository) List(ctx context.Context, filter minicommerce.ProductFilter, opts minicommerce.ListOptions) (*minicommerce.ProductPage, error) {
+	dir := direction(opts.Order)
+	query := p.client.Collection(productsCollection).Query
+	if filter.Active != nil {
+		query = query.Where("active", "==", *filter.Active)
+	}
+	if filter.Type != "" {
+		query = query.Where("type", "==", string(filter.Type))
+	}
+	query = query.OrderBy("created", dir).OrderBy(firestore.DocumentID, dir)
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.StartAfter(c.Created, c.ID)
+	}
+
+	// one more document than the limit is read to know if there is a next page
+	n := limit(opts)
+	docs, err := query.Limit(n + 1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &minicommerce.ProductPage{
+		Products: make([]minicommerce.Product, 0, n),
+	}
+	for i, doc := range docs {
+		if i == n {
+			last := page.Products[n-1]
+			page.NextCursor = cursor{Created: last.Created, ID: last.ID}.encode()
+			break
+		}
+
+		product := minicommerce.Product{
+			ID: doc.Ref.ID,
+		}
+		if err := doc.DataTo(&product); err != nil {
+			return nil, err
+		}
+
+		page.Products = append(page.Products, product)
+	}
+
+	return page, nil
+}
+
 // Get ...
 func (p *ProductRepository) Get(ctx context.Context, id string) (*minicommerce.Product, error) {
 	docRef := p.client.Collection(productsCollection).Doc(id)
 	snapshot, err := docRef.Get(ctx)
-	if err != nil {
-		return nil, err
+	if isNotFound(err) {
+		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", productsCollection, id)}
 	}
 
-	if !snapshot.Exists() {
-		return nil, &DocumentNotFoundError{fmt.Sprintf("%s/%s", downloadableCollection, id)}
+	if err != nil {
+		return nil, err
 	}
 
 	product := &minicommerce.Product{
@@ -68,6 +119,30 @@ func (p *ProductRepository) Get(ctx context.Context, id string) (*minicommerce.P
 	return product, nil
 }
 
+// DownloadableReferenced reports whether any product embeds the downloadable. Firestore can't query on a field
+// of the embedded downloadables, so all the products are read and checked
+func (p *ProductRepository) DownloadableReferenced(ctx context.Context, downloadableID string) (bool, error) {
+	docs, err := p.client.Collection(productsCollection).Select("downloadable").Documents(ctx).GetAll()
+	if err != nil {
+		return false, err
+	}
+
+	for _, d := range docs {
+		var product minicommerce.Product
+		if err := d.DataTo(&product); err != nil {
+			return false, err
+		}
+
+		for _, downloadable := range product.Downloadable {
+			if downloadable.ID == downloadableID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 // Create ...
 func (p *ProductRepository) Create(ctx context.Context, product *minicommerce.Product) error {
 	docRef := p.client.Collection(productsCollection).Doc(product.ID)
@@ -87,3 +162,13 @@ func (p *ProductRepository) Update(ctx context.Context, product *minicommerce.Pr
 
 	return nil
 }
+
+// Delete ...
+func (p *ProductRepository) Delete(ctx context.Context, id string) error {
+	docRef := p.client.Collection(productsCollection).Doc(id)
+	if _, err := docRef.Delete(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package firestore
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/bradleyjkemp/cupaloy/v2"
@@ -127,6 +128,69 @@ func TestGetAllProducts(t *testing.T) {
 	cupaloy.SnapshotT(t, docs)
 }
 
+func TestListProducts(t *testing.T) {
+	ctx := context.Background()
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	pp := []minicommerce.Product{
+		{ID: "list-one", Created: 1, Type: minicommerce.ProductTypeDigital, Active: true},
+		{ID: "list-two", Created: 2, Type: minicommerce.ProductTypeShippable, Active: true},
+		{ID: "list-three", Created: 3, Type: minicommerce.ProductTypeDigital, Active: false},
+		{ID: "list-four", Created: 4, Type: minicommerce.ProductTypeDigital, Active: true},
+	}
+
+	defer func() {
+		for _, p := range pp {
+			c.Collection(productsCollection).Doc(p.ID).Delete(ctx)
+		}
+		c.Close()
+	}()
+
+	for _, p := range pp {
+		if _, err := c.Collection(productsCollection).Doc(p.ID).Set(ctx, p); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	repo := NewProductRepository(c)
+
+	active := true
+	filter := minicommerce.ProductFilter{Active: &active, Type: minicommerce.ProductTypeDigital}
+	opts := minicommerce.ListOptions{Limit: 1, Order: minicommerce.SortDescending}
+
+	var pages [][]string
+	for {
+		page, err := repo.List(ctx, filter, opts)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		var ids []string
+		for _, p := range page.Products {
+			ids = append(ids, p.ID)
+		}
+		pages = append(pages, ids)
+
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	expected := [][]string{{"list-four"}, {"list-one"}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("expected the pages %v, got %v", expected, pages)
+	}
+
+	_, err = repo.List(ctx, filter, minicommerce.ListOptions{Cursor: "not-a-cursor"})
+	if _, ok := err.(*InvalidCursorError); !ok {
+		t.Errorf("expected an invalid cursor error, got %v", err)
+	}
+}
+
 func TestCreateProduct(t *testing.T) {
 	ctx := context.Background()
 	ID := "testing-product-create"
@@ -192,3 +256,77 @@ func TestUpdateProduct(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestDeleteProduct(t *testing.T) {
+	ctx := context.Background()
+	ID := "testing-product-delete"
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	defer cleanup(c, productsCollection, ID)
+
+	if _, err := c.Collection(productsCollection).Doc(ID).Set(ctx, minicommerce.Product{ID: ID}); err != nil {
+		t.Errorf(err.Error())
+	}
+
+	repo := NewProductRepository(c)
+
+	if err := repo.Delete(ctx, ID); err != nil {
+		t.Errorf(err.Error())
+	}
+
+	if _, err := repo.Get(ctx, ID); !isDocumentNotFound(err) {
+		t.Errorf("expected the product to be deleted, got %v", err)
+	}
+}
+
+func TestGetProductNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	defer c.Close()
+
+	repo := NewProductRepository(c)
+	_, err = repo.Get(ctx, "product-does-not-exist")
+	if !isDocumentNotFound(err) {
+		t.Errorf("expected a DocumentNotFoundError, got %v", err)
+	}
+}
+
+func TestDownloadableReferenced(t *testing.T) {
+	ctx := context.Background()
+	p := minicommerce.Product{
+		ID:           "downloadable-referenced-product",
+		Type:         minicommerce.ProductTypeDigital,
+		Downloadable: []minicommerce.Downloadable{{ID: "referenced-downloadable"}},
+	}
+
+	c, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	defer cleanup(c, productsCollection, p.ID)
+
+	if _, err := c.Collection(productsCollection).Doc(p.ID).Set(ctx, p); err != nil {
+		t.Error(err.Error())
+	}
+
+	repo := NewProductRepository(c)
+
+	referenced, err := repo.DownloadableReferenced(ctx, "referenced-downloadable")
+	if err != nil || !referenced {
+		t.Errorf("expected the downloadable to be referenced, got %v and %v", referenced, err)
+	}
+
+	referenced, err = repo.DownloadableReferenced(ctx, "unreferenced-downloadable")
+	if err != nil || referenced {
+		t.Errorf("expected the downloadable not to be referenced, got %v and %v", referenced, err)
+	}
+}
@@ -0,0 +1,138 @@
+package gc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/eikc/minicommerce"
+)
+
+// Collector reconciles the files in the downloadables folder with the downloadables that point at them. Uploads
+// that fail half way and deleted downloadables can leave files behind, and files can go missing from the storage.
+// Nothing outside the folder is ever deleted, the storage can hold files that don't belong to the downloadables
+type Collector struct {
+	downloadableReader minicommerce.DownloadableReader
+	storageLister      minicommerce.StorageLister
+	storageDeleter     minicommerce.StorageDeleter
+	timeService        minicommerce.TimeService
+}
+
+// Options controls what a collection deletes. Orphaned files younger than the grace period, in seconds, are kept,
+// as they can belong to an upload that is still in progress. A dry run only reports
+type Options struct {
+	GracePeriod int64
+	DryRun      bool
+}
+
+// Report is the outcome of a collection. Orphaned are the files no downloadable points at, Missing are
+// the downloadables whose file doesn't exist. Expired are the locations of the orphaned files older than
+// the grace period, they have been deleted unless it was a dry run
+type Report struct {
+	Orphaned []minicommerce.StorageObject
+	Missing  []minicommerce.Downloadable
+	Expired  []string
+}
+
+// NewCollector is the constructor for the Collector
+func NewCollector(downloadableReader minicommerce.DownloadableReader,
+	storageLister minicommerce.StorageLister,
+	storageDeleter minicommerce.StorageDeleter,
+	timeService minicommerce.TimeService) *Collector {
+
+	return &Collector{
+		downloadableReader: downloadableReader,
+		storageLister:      storageLister,
+		storageDeleter:     storageDeleter,
+		timeService:        timeService,
+	}
+}
+
+// Run compares the files with the downloadables and deletes the expired orphans. The files are listed before
+// the downloadables are read, so a file whose downloadable is created in between is never seen as an orphan.
+// The legacy files outside the folder are only looked up to find the missing ones.
+// A delete that fails stops the collection
+func (c *Collector) Run(ctx context.Context, opts Options) (*Report, error) {
+	objects, err := c.storageLister.List(ctx, minicommerce.DownloadablesFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadables, err := c.downloadableReader.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy, err := c.legacyObjects(ctx, downloadables)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	referenced := make(map[string]bool, len(downloadables))
+	for _, downloadable := range downloadables {
+		referenced[downloadable.Location] = true
+	}
+
+	stored := make(map[string]bool, len(objects))
+	expiry := c.timeService.Now() - opts.GracePeriod
+	for _, obj := range objects {
+		stored[obj.Location] = true
+		if referenced[obj.Location] {
+			continue
+		}
+
+		report.Orphaned = append(report.Orphaned, obj)
+		if obj.ModTime <= expiry {
+			report.Expired = append(report.Expired, obj.Location)
+		}
+	}
+
+	for _, obj := range legacy {
+		stored[obj.Location] = true
+	}
+
+	for _, downloadable := range downloadables {
+		if !stored[downloadable.Location] {
+			report.Missing = append(report.Missing, downloadable)
+		}
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	for _, location := range report.Expired {
+		if err := c.storageDeleter.Delete(ctx, location); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// legacyObjects looks up the files the downloadables point at outside the downloadables folder,
+// by their exact location, as the files around them aren't necessarily downloadables
+func (c *Collector) legacyObjects(ctx context.Context, downloadables []minicommerce.Downloadable) ([]minicommerce.StorageObject, error) {
+	var legacy []minicommerce.StorageObject
+	looked := make(map[string]bool)
+	for _, downloadable := range downloadables {
+		location := downloadable.Location
+		if strings.HasPrefix(location, minicommerce.DownloadablesFolder) || looked[location] {
+			continue
+		}
+		looked[location] = true
+
+		objects, err := c.storageLister.List(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects {
+			if obj.Location == location {
+				legacy = append(legacy, obj)
+			}
+		}
+	}
+
+	return legacy, nil
+}
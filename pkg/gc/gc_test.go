@@ -0,0 +1,104 @@
+package gc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+// the objects are listed a week after the old ones were written
+const (
+	day  = 24 * 60 * 60
+	week = 7 * day
+	now  = week
+)
+
+var objects = []minicommerce.StorageObject{
+	{Location: "downloadables/pdf/book.pdf", Size: 8, ModTime: 0},
+	{Location: "downloadables/old/failed.pdf", Size: 4, ModTime: 0},
+	{Location: "downloadables/new/uploading.pdf", Size: 4, ModTime: now - 60},
+}
+
+// the legacy files at the root of the storage, only the ones the downloadables point at are looked up
+var legacyObjects = []minicommerce.StorageObject{
+	{Location: "ebook.pdf", Size: 8, ModTime: 0},
+	{Location: "ebook.pdf.bak", Size: 8, ModTime: 0},
+}
+
+var downloadables = []minicommerce.Downloadable{
+	{ID: "pdf", Location: "downloadables/pdf/book.pdf"},
+	{ID: "shared", Location: "downloadables/pdf/book.pdf"},
+	{ID: "legacy", Location: "ebook.pdf"},
+	{ID: "lost", Location: "downloadables/lost/video.mp4"},
+	{ID: "legacy-lost", Location: "manual.pdf"},
+}
+
+func setupCollector(t *testing.T) (*Collector, *mocks.MockDownloadableRepository, *mocks.MockStorage, func()) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockDownloadableRepository(ctrl)
+	storage := mocks.NewMockStorage(ctrl)
+	time := mocks.NewMockTimeService(ctrl)
+
+	storage.EXPECT().List(gomock.Any(), "downloadables/").Times(1).Return(objects, nil)
+	repo.EXPECT().GetAll(gomock.Any()).Times(1).Return(downloadables, nil)
+	storage.EXPECT().List(gomock.Any(), "ebook.pdf").Times(1).Return(legacyObjects, nil)
+	storage.EXPECT().List(gomock.Any(), "manual.pdf").Times(1).Return(nil, nil)
+	time.EXPECT().Now().Times(1).Return(int64(now))
+
+	return NewCollector(repo, storage, storage, time), repo, storage, func() {
+		ctrl.Finish()
+	}
+}
+
+func TestRun(t *testing.T) {
+	collector, _, storage, finalize := setupCollector(t)
+	defer finalize()
+
+	storage.EXPECT().Delete(gomock.Any(), "downloadables/old/failed.pdf").Times(1).Return(nil)
+
+	report, err := collector.Run(context.Background(), Options{GracePeriod: day})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := &Report{
+		Orphaned: []minicommerce.StorageObject{objects[1], objects[2]},
+		Missing:  []minicommerce.Downloadable{downloadables[3], downloadables[4]},
+		Expired:  []string{"downloadables/old/failed.pdf"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected the report to be %v, got %v", expected, report)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	collector, _, _, finalize := setupCollector(t)
+	defer finalize()
+
+	report, err := collector.Run(context.Background(), Options{GracePeriod: day, DryRun: true})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(report.Expired) != 1 || len(report.Orphaned) != 2 || len(report.Missing) != 2 {
+		t.Errorf("expected the dry run to report the same as a real run, got %v", report)
+	}
+}
+
+func TestRunGracePeriod(t *testing.T) {
+	collector, _, _, finalize := setupCollector(t)
+	defer finalize()
+
+	report, err := collector.Run(context.Background(), Options{GracePeriod: 2 * week})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(report.Expired) != 0 {
+		t.Errorf("expected no orphan to be older than the grace period, got %v", report.Expired)
+	}
+}
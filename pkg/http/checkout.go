@@ -0,0 +1,22 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func (s *Server) postCheckout() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		order, err := s.checkoutService.Checkout(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, order)
+	}
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/checkout"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestPostCheckout(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		order *minicommerce.Order
+		err   error
+	}{
+		{
+			desc: "Checking out an order will return the order with the payment attached",
+			order: &minicommerce.Order{
+				ID:        "order-one",
+				PaymentID: "payment-one",
+				Items: []minicommerce.Product{
+					{ID: "product-one", Price: 10000, Active: true},
+				},
+				Amount:    10000,
+				NetAmount: 10000,
+				Taxes:     2500,
+				Total:     12500,
+			},
+		},
+		{
+			desc: "When the order does not exist, it will return 404",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+		{
+			desc: "When the cart is empty, it will return 400",
+			err:  &checkout.EmptyCartError{},
+		},
+		{
+			desc: "When the payment is declined, it will return 402",
+			err:  &minicommerce.PaymentDeclinedError{Reason: "insufficient funds"},
+		},
+		{
+			desc: "When the coupon is rejected, it will return 422",
+			err:  &coupons.RejectionError{Code: "EXPIRED", Reason: coupons.ReasonExpired},
+		},
+		{
+			desc: "When the coupon has been redeemed the maximum number of times, it will return 422",
+			err:  &minicommerce.CouponExhaustedError{Code: "TENOFF"},
+		},
+		{
+			desc: "When the order is already checked out, it will return 409",
+			err:  &minicommerce.AlreadyCheckedOutError{},
+		},
+		{
+			desc: "When the checkout fails, it will return 500",
+			err:  errors.New("some test error occurred"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mocks.NewMockCheckoutService(ctrl)
+			service.EXPECT().Checkout(gomock.Any(), "order-one").Times(1).Return(tC.order, tC.err)
+
+			server := Server{
+				checkoutService: service,
+				router:          httprouter.New(),
+			}
+
+			server.routes()
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/orders/%s/checkout", "order-one"), nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/julienschmidt/httprouter"
+)
+
+type couponRequest struct {
+	Coupon struct {
+		Code           string                     `json:"code"`
+		Description    string                     `json:"description"`
+		Active         bool                       `json:"active"`
+		AmountOff      int64                      `json:"amountOff"`
+		PercentOff     float64                    `json:"percentOff"`
+		MaxRedemptions int64                      `json:"maxRedemptions"`
+		RedeemBy       int64                      `json:"redeemBy"`
+		RedeemBefore   int64                      `json:"redeemBefore"`
+		ProductIDs     []string                   `json:"productIds"`
+		ProductTypes   []minicommerce.ProductType `json:"productTypes"`
+		MinimumAmount  int64                      `json:"minimumAmount"`
+		FirstOrderOnly bool                       `json:"firstOrderOnly"`
+	} `json:"coupon"`
+}
+
+func (s *Server) getAllCoupons() httprouter.Handle {
+	type response struct {
+		Collection []minicommerce.Coupon `json:"collection"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		ctx := r.Context()
+
+		coupons, err := s.couponRepository.GetAll(ctx)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		resp := response{
+			Collection: make([]minicommerce.Coupon, 0),
+		}
+		resp.Collection = append(resp.Collection, coupons...)
+
+		sendJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) getCouponByCode() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		code := params.ByName("code")
+
+		coupon, err := s.couponRepository.GetByCode(ctx, code)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, coupon)
+	}
+}
+
+func (s *Server) postCoupon() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		ctx := r.Context()
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request couponRequest
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		coupon := minicommerce.Coupon{
+			ID:             request.Coupon.Code,
+			Description:    request.Coupon.Description,
+			Active:         request.Coupon.Active,
+			AmountOff:      request.Coupon.AmountOff,
+			PercentOff:     request.Coupon.PercentOff,
+			MaxRedemptions: request.Coupon.MaxRedemptions,
+			RedeemBy:       request.Coupon.RedeemBy,
+			RedeemBefore:   request.Coupon.RedeemBefore,
+			ProductIDs:     request.Coupon.ProductIDs,
+			ProductTypes:   request.Coupon.ProductTypes,
+			MinimumAmount:  request.Coupon.MinimumAmount,
+			FirstOrderOnly: request.Coupon.FirstOrderOnly,
+		}
+
+		if fields := couponFieldErrors(coupon, s.timeService.Now(), true); len(fields) > 0 {
+			sendError(w, &minicommerce.ValidationError{Fields: fields})
+			return
+		}
+
+		if err := s.couponRepository.Create(ctx, coupon); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, coupon)
+	}
+}
+
+func (s *Server) putCoupon() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		code := params.ByName("code")
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request couponRequest
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		coupon, err := s.couponRepository.GetByCode(ctx, code)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		// the code is the identity of the coupon and the redemptions are counted at checkout,
+		// so neither of them can be changed here
+		coupon.Description = request.Coupon.Description
+		coupon.Active = request.Coupon.Active
+		coupon.AmountOff = request.Coupon.AmountOff
+		coupon.PercentOff = request.Coupon.PercentOff
+		coupon.MaxRedemptions = request.Coupon.MaxRedemptions
+		coupon.RedeemBy = request.Coupon.RedeemBy
+		coupon.RedeemBefore = request.Coupon.RedeemBefore
+		coupon.ProductIDs = request.Coupon.ProductIDs
+		coupon.ProductTypes = request.Coupon.ProductTypes
+		coupon.MinimumAmount = request.Coupon.MinimumAmount
+		coupon.FirstOrderOnly = request.Coupon.FirstOrderOnly
+
+		if fields := couponFieldErrors(*coupon, s.timeService.Now(), false); len(fields) > 0 {
+			sendError(w, &minicommerce.ValidationError{Fields: fields})
+			return
+		}
+
+		if err := s.couponRepository.Update(ctx, *coupon); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, coupon)
+	}
+}
+
+func (s *Server) postDeactivateCoupon() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		code := params.ByName("code")
+
+		coupon, err := s.couponRepository.GetByCode(ctx, code)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if !coupon.Active {
+			sendJSON(w, http.StatusOK, coupon)
+			return
+		}
+
+		coupon.Active = false
+		if err := s.couponRepository.Update(ctx, *coupon); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, coupon)
+	}
+}
+
+func (s *Server) postCouponBatch() httprouter.Handle {
+	type request struct {
+		couponRequest
+		Count int `json:"count"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		ctx := r.Context()
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request request
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		template := minicommerce.Coupon{
+			ID:             request.Coupon.Code,
+			Description:    request.Coupon.Description,
+			Active:         request.Coupon.Active,
+			AmountOff:      request.Coupon.AmountOff,
+			PercentOff:     request.Coupon.PercentOff,
+			RedeemBy:       request.Coupon.RedeemBy,
+			RedeemBefore:   request.Coupon.RedeemBefore,
+			ProductIDs:     request.Coupon.ProductIDs,
+			ProductTypes:   request.Coupon.ProductTypes,
+			MinimumAmount:  request.Coupon.MinimumAmount,
+			FirstOrderOnly: request.Coupon.FirstOrderOnly,
+		}
+
+		// the code of the template is used as prefix for all the generated codes, so it is optional
+		fields := couponFieldErrors(template, s.timeService.Now(), false)
+		if request.Count < 1 || request.Count > coupons.MaxGenerate {
+			fields = append(fields, minicommerce.FieldError{
+				Field:   "count",
+				Message: fmt.Sprintf("must be between 1 and %d", coupons.MaxGenerate),
+			})
+		}
+		if len(fields) > 0 {
+			sendError(w, &minicommerce.ValidationError{Fields: fields})
+			return
+		}
+
+		generated, err := s.couponGenerator.Generate(ctx, template, request.Count)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		// the codes can be exported again from the batch
+		batch := generated[0].Batch
+		w.Header().Set("Location", "/api/coupon-batches/"+url.PathEscape(batch))
+		sendCouponBatch(w, r, batch, generated)
+	}
+}
+
+func (s *Server) getCouponBatch() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		batch := params.ByName("id")
+
+		coupons, err := s.couponRepository.GetByBatch(ctx, batch)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if len(coupons) == 0 {
+			sendError(w, &requestError{status: http.StatusNotFound, code: codeNotFound, message: "The batch does not exist"})
+			return
+		}
+
+		sendCouponBatch(w, r, batch, coupons)
+	}
+}
+
+// sendCouponBatch sends the coupons of the batch as JSON, or their codes as csv when the client accepts it
+func sendCouponBatch(w http.ResponseWriter, r *http.Request, batch string, coupons []minicommerce.Coupon) {
+	type response struct {
+		Batch      string                `json:"batch"`
+		Collection []minicommerce.Coupon `json:"collection"`
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		sendCouponCodesCSV(w, coupons)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, response{Batch: batch, Collection: coupons})
+}
+
+// sendCouponCodesCSV writes the codes of the coupons as a csv file with a single code column
+func sendCouponCodesCSV(w http.ResponseWriter, coupons []minicommerce.Coupon) error {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="coupons.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"code"})
+	for _, c := range coupons {
+		writer.Write([]string{c.ID})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// couponFieldErrors makes sure the coupon gives a sensible discount and can still be redeemed at the unix time now.
+// The code is only required when the coupon is created, it can never contain slashes since it is used as document ID
+func couponFieldErrors(coupon minicommerce.Coupon, now int64, requireCode bool) []minicommerce.FieldError {
+	var fields []minicommerce.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, minicommerce.FieldError{Field: field, Message: message})
+	}
+
+	if requireCode && coupon.ID == "" {
+		invalid("code", "is required")
+	}
+	if strings.Contains(coupon.ID, "/") {
+		invalid("code", "can not contain any slashes")
+	}
+	if coupon.AmountOff < 0 {
+		invalid("amountOff", "can not be negative")
+	}
+	if coupon.PercentOff < 0 || coupon.PercentOff > 100 {
+		invalid("percentOff", "must be between 0 and 100")
+	}
+	if coupon.AmountOff > 0 && coupon.PercentOff > 0 {
+		invalid("percentOff", "can not be combined with amountOff")
+	}
+	if coupon.MaxRedemptions < 0 {
+		invalid("maxRedemptions", "can not be negative")
+	}
+	if coupon.RedeemBy != 0 && coupon.RedeemBy < now {
+		invalid("redeemBy", "must be in the future")
+	}
+	if coupon.RedeemBefore != 0 && coupon.RedeemBefore <= now {
+		invalid("redeemBefore", "must be in the future")
+	}
+	if coupon.MinimumAmount < 0 {
+		invalid("minimumAmount", "can not be negative")
+	}
+
+	for _, t := range coupon.ProductTypes {
+		switch t {
+		case minicommerce.ProductTypeDigital, minicommerce.ProductTypeLink, minicommerce.ProductTypeShippable:
+		default:
+			invalid("productTypes", fmt.Sprintf("contains the unknown product type: %s", t))
+		}
+	}
+
+	return fields
+}
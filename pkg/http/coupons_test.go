@@ -0,0 +1,451 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+)
+
+func setupCouponHTTPServer(t *testing.T) (*Server, *mocks.MockCouponRepository,
+	*mocks.MockCouponGenerator,
+	*mocks.MockTimeService,
+	func()) {
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockCouponRepository(ctrl)
+	generator := mocks.NewMockCouponGenerator(ctrl)
+	time := mocks.NewMockTimeService(ctrl)
+
+	server := Server{
+		couponRepository: repo,
+		couponGenerator:  generator,
+		timeService:      time,
+		router:           httprouter.New(),
+	}
+
+	server.routes()
+
+	return &server, repo, generator, time, func() {
+		ctrl.Finish()
+	}
+}
+
+func TestCoupons_GetAllCoupons(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		coupons []minicommerce.Coupon
+		err     error
+	}{
+		{
+			desc: "Get all will return the collection of coupons",
+			coupons: []minicommerce.Coupon{
+				{ID: "TENOFF", Description: "10% off", Active: true, PercentOff: 10, MaxRedemptions: 100, Redemptions: 12},
+				{ID: "FIVEHUNDRED", Description: "500 off", AmountOff: 500},
+			},
+		},
+		{
+			desc: "When no coupons exist, it will return an empty array as response",
+		},
+		{
+			desc: "When the repository fails, we return an http 500",
+			err:  errors.New("some test error occurred"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().GetAll(gomock.Any()).Times(1).Return(tC.coupons, tC.err)
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, "/api/coupons", nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestCoupons_GetCouponByCode(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		coupon *minicommerce.Coupon
+		err    error
+	}{
+		{
+			desc:   "Getting a coupon by code will return the correct coupon",
+			coupon: &minicommerce.Coupon{ID: "TENOFF", Description: "10% off", Active: true, PercentOff: 10},
+		},
+		{
+			desc: "When no coupon exists, it will return 404",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().GetByCode(gomock.Any(), "TENOFF").Times(1).Return(tC.coupon, tC.err)
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/coupons/%s", "TENOFF"), nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestCoupons_PostCoupon(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		body      string
+		createErr error
+		creates   bool
+	}{
+		{
+			desc:    "Post coupon will create the coupon",
+			body:    `{"coupon":{"code":"TENOFF","description":"10% off","active":true,"percentOff":10,"maxRedemptions":100,"redeemBefore":2000}}`,
+			creates: true,
+		},
+		{
+			desc:    "Post coupon will create a coupon restricted to digital products on first orders",
+			body:    `{"coupon":{"code":"WELCOME","active":true,"percentOff":15,"productTypes":["digital"],"minimumAmount":10000,"firstOrderOnly":true}}`,
+			creates: true,
+		},
+		{
+			desc: "An unknown product type will return 422 with the invalid fields",
+			body: `{"coupon":{"code":"WELCOME","active":true,"percentOff":15,"productTypes":["physical"]}}`,
+		},
+		{
+			desc: "A coupon without a code will return 422 with the invalid fields",
+			body: `{"coupon":{"description":"10% off","active":true,"percentOff":10}}`,
+		},
+		{
+			desc: "A percentOff above 100 will return 422 with the invalid fields",
+			body: `{"coupon":{"code":"TOOMUCH","active":true,"percentOff":110}}`,
+		},
+		{
+			desc: "A coupon with both amountOff and percentOff will return 422 with the invalid fields",
+			body: `{"coupon":{"code":"BOTH","active":true,"amountOff":500,"percentOff":10}}`,
+		},
+		{
+			desc: "A redeemBefore in the past will return 422 with the invalid fields",
+			body: `{"coupon":{"code":"EXPIRED","active":true,"amountOff":500,"redeemBefore":500}}`,
+		},
+		{
+			desc:      "When the coupon already exists, it will return 409",
+			body:      `{"coupon":{"code":"TENOFF","active":true,"percentOff":10}}`,
+			createErr: &firestore.DocumentExistsError{},
+			creates:   true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, time, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			time.EXPECT().Now().AnyTimes().Return(int64(1000))
+
+			var created minicommerce.Coupon
+			if tC.creates {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ interface{}, c minicommerce.Coupon) {
+					created = c
+				}).Times(1).Return(tC.createErr)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPost, "/api/coupons", bytes.NewReader([]byte(tC.body)))
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status  int
+				body    string
+				created minicommerce.Coupon
+			}{
+				status:  recorder.Code,
+				body:    recorder.Body.String(),
+				created: created,
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestCoupons_PutCoupon(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		body    string
+		coupon  *minicommerce.Coupon
+		err     error
+		updates bool
+	}{
+		{
+			desc:    "Put coupon will replace the coupon but keep the redemptions",
+			body:    `{"coupon":{"description":"500 off","active":true,"amountOff":500,"maxRedemptions":50,"redemptions":0}}`,
+			coupon:  &minicommerce.Coupon{ID: "TENOFF", Description: "10% off", Active: true, PercentOff: 10, MaxRedemptions: 100, Redemptions: 12},
+			updates: true,
+		},
+		{
+			desc:   "An invalid coupon will return 422 with the invalid fields",
+			body:   `{"coupon":{"active":true,"percentOff":-5}}`,
+			coupon: &minicommerce.Coupon{ID: "TENOFF", Active: true, PercentOff: 10},
+		},
+		{
+			desc: "When no coupon exists, it will return 404",
+			body: `{"coupon":{"active":true,"percentOff":10}}`,
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, time, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			time.EXPECT().Now().AnyTimes().Return(int64(1000))
+			repo.EXPECT().GetByCode(gomock.Any(), "TENOFF").Times(1).Return(tC.coupon, tC.err)
+
+			var updated minicommerce.Coupon
+			if tC.updates {
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ interface{}, c minicommerce.Coupon) {
+					updated = c
+				}).Times(1).Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/coupons/%s", "TENOFF"), bytes.NewReader([]byte(tC.body)))
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status  int
+				body    string
+				updated minicommerce.Coupon
+			}{
+				status:  recorder.Code,
+				body:    recorder.Body.String(),
+				updated: updated,
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestCoupons_DeactivateCoupon(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		coupon  *minicommerce.Coupon
+		err     error
+		updates bool
+	}{
+		{
+			desc:    "Deactivating an active coupon will update it",
+			coupon:  &minicommerce.Coupon{ID: "TENOFF", Active: true, PercentOff: 10, Redemptions: 3},
+			updates: true,
+		},
+		{
+			desc:   "Deactivating an inactive coupon will not update it",
+			coupon: &minicommerce.Coupon{ID: "TENOFF", PercentOff: 10},
+		},
+		{
+			desc: "When no coupon exists, it will return 404",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().GetByCode(gomock.Any(), "TENOFF").Times(1).Return(tC.coupon, tC.err)
+			if tC.updates {
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/coupons/%s/deactivate", "TENOFF"), nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestCoupons_PostCouponBatch(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		body      string
+		accept    string
+		generates bool
+	}{
+		{
+			desc:      "Posting a batch will return the generated coupons",
+			body:      `{"coupon":{"code":"SUMMER-","description":"Summer campaign","active":true,"percentOff":20},"count":2}`,
+			generates: true,
+		},
+		{
+			desc:      "Posting a batch accepting csv will return the generated codes as csv",
+			body:      `{"coupon":{"code":"SUMMER-","description":"Summer campaign","active":true,"percentOff":20},"count":2}`,
+			accept:    "text/csv",
+			generates: true,
+		},
+		{
+			desc: "A count outside the allowed range will return 422 with the invalid fields",
+			body: `{"coupon":{"code":"SUMMER-","active":true,"percentOff":20},"count":0}`,
+		},
+		{
+			desc: "An invalid template will return 422 with the invalid fields",
+			body: `{"coupon":{"code":"SUMMER-","active":true,"percentOff":200},"count":2}`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, _, generator, time, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			time.EXPECT().Now().AnyTimes().Return(int64(1000))
+			if tC.generates {
+				generator.EXPECT().Generate(gomock.Any(), gomock.Any(), 2).Times(1).Return([]minicommerce.Coupon{
+					{ID: "SUMMER-ABCDEFGHJK", Batch: "batch1", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 1},
+					{ID: "SUMMER-23456789LM", Batch: "batch1", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 1},
+				}, nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPost, "/api/coupon-batches", bytes.NewReader([]byte(tC.body)))
+			if err != nil {
+				t.Error(err.Error())
+			}
+			r.Header.Set("Accept", tC.accept)
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status      int
+				contentType string
+				location    string
+				body        string
+			}{
+				status:      recorder.Code,
+				contentType: recorder.Header().Get("Content-Type"),
+				location:    recorder.Header().Get("Location"),
+				body:        recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestCoupons_GetCouponBatch(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		accept  string
+		coupons []minicommerce.Coupon
+	}{
+		{
+			desc: "Getting a batch will return its coupons",
+			coupons: []minicommerce.Coupon{
+				{ID: "SUMMER-ABCDEFGHJK", Batch: "batch1", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 1},
+				{ID: "SUMMER-23456789LM", Batch: "batch1", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 1},
+			},
+		},
+		{
+			desc:   "Getting a batch accepting csv will return its codes as csv",
+			accept: "text/csv",
+			coupons: []minicommerce.Coupon{
+				{ID: "SUMMER-ABCDEFGHJK", Batch: "batch1", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 1},
+				{ID: "SUMMER-23456789LM", Batch: "batch1", Description: "Summer campaign", Active: true, PercentOff: 20, MaxRedemptions: 1},
+			},
+		},
+		{
+			desc: "Getting a batch without coupons will return 404",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, finalize := setupCouponHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().GetByBatch(gomock.Any(), "batch1").Times(1).Return(tC.coupons, nil)
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, "/api/coupon-batches/batch1", nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+			r.Header.Set("Accept", tC.accept)
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status      int
+				contentType string
+				body        string
+			}{
+				status:      recorder.Code,
+				contentType: recorder.Header().Get("Content-Type"),
+				body:        recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
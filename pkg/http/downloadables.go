@@ -1,7 +1,9 @@
 package http
 
 import (
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gofrs/uuid"
 
@@ -18,20 +20,29 @@ func (s *Server) getAllDownloadables() httprouter.Handle {
 
 	type response struct {
 		Collection []downloadableItem `json:"collection"`
+		Links      links              `json:"links"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		ctx := r.Context()
-		downloadables, err := s.downloadableRepository.GetAll(ctx)
+
+		opts, err := listOptions(r.URL.Query())
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		page, err := s.downloadableRepository.List(ctx, opts)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			sendError(w, err)
 			return
 		}
 
 		resp := response{
 			Collection: make([]downloadableItem, 0),
+			Links:      pageLinks(r, page.NextCursor),
 		}
-		for _, downloadable := range downloadables {
+		for _, downloadable := range page.Downloadables {
 			d := downloadableItem{
 				ID:   downloadable.ID,
 				Name: downloadable.Name,
@@ -46,16 +57,19 @@ func (s *Server) getAllDownloadables() httprouter.Handle {
 
 func (s *Server) postDownloadables() httprouter.Handle {
 	type response struct {
-		ID       string `json:"id,omitempty"`
-		Name     string `json:"name,omitempty"`
-		Location string `json:"location,omitempty"`
+		ID          string `json:"id,omitempty"`
+		Name        string `json:"name,omitempty"`
+		Location    string `json:"location,omitempty"`
+		Hash        string `json:"hash,omitempty"`
+		Size        int64  `json:"size"`
+		ContentType string `json:"contentType,omitempty"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		ctx := r.Context()
 		file, handler, err := r.FormFile("file")
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			sendError(w, badRequest("The request must contain a file in the file field"))
 			return
 		}
 		defer file.Close()
@@ -66,33 +80,114 @@ func (s *Server) postDownloadables() httprouter.Handle {
 
 		ID, err := uuid.NewV4()
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			sendError(w, err)
 			return
 		}
 
 		downloadable := minicommerce.Downloadable{
 			ID:       ID.String(),
 			Name:     handler.Filename,
-			Location: handler.Filename,
+			Location: minicommerce.DownloadableLocation(ID.String(), handler.Filename),
 		}
 
-		if err := s.downloadableRepository.Create(ctx, &downloadable); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		upload := newDigest(file)
+		metadata := &minicommerce.StorageMetadata{ContentType: upload.contentType(handler.Filename)}
+		if err := s.storage.Write(ctx, downloadable.Location, upload, metadata); err != nil {
+			sendError(w, err)
 			return
 		}
 
-		if err := s.storage.Write(ctx, handler.Filename, file); err != nil {
-			s.downloadableRepository.Delete(ctx, ID.String())
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		downloadable.Hash = upload.sum()
+		downloadable.Size = upload.size
+		downloadable.ContentType = metadata.ContentType
+
+		uploaded := downloadable.Location
+		if err := s.downloadableRepository.CreateSharingFile(ctx, &downloadable); err != nil {
+			s.storage.Delete(ctx, uploaded)
+			sendError(w, err)
 			return
 		}
 
+		// the downloadable shares the file of another one with the same content, so the upload isn't needed
+		if downloadable.Location != uploaded {
+			if err := s.storage.Delete(ctx, uploaded); err != nil {
+				log.Printf("could not delete the duplicate upload %s: %v", uploaded, err)
+			}
+		}
+
 		resp := response{
-			ID:       downloadable.ID,
-			Name:     downloadable.Name,
-			Location: downloadable.Location,
+			ID:          downloadable.ID,
+			Name:        downloadable.Name,
+			Location:    downloadable.Location,
+			Hash:        downloadable.Hash,
+			Size:        downloadable.Size,
+			ContentType: downloadable.ContentType,
 		}
 
 		sendJSON(w, 200, resp)
 	}
 }
+
+// deleteDownloadable deletes the downloadable and its file. A downloadable that is embedded in a product is only deleted
+// when force is true, the products then keep their copy of it, but it can't be downloaded anymore. A downloadable
+// that has been bought is never deleted, not even with force, as the buyers must still be able to download it
+func (s *Server) deleteDownloadable() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		force := false
+		if f := r.URL.Query().Get("force"); f != "" {
+			var err error
+			if force, err = strconv.ParseBool(f); err != nil {
+				sendError(w, badRequest("The force parameter must be either true or false"))
+				return
+			}
+		}
+
+		downloadable, err := s.downloadableRepository.Get(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if !force {
+			referenced, err := s.downloadableReferenceChecker.DownloadableReferenced(ctx, id)
+			if err != nil {
+				sendError(w, err)
+				return
+			}
+
+			if referenced {
+				sendError(w, conflict("The downloadable is part of existing products, remove it from them or delete it with force=true"))
+				return
+			}
+		}
+
+		purchased, err := s.downloadService.Purchased(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if purchased {
+			sendError(w, conflict("The downloadable has been bought, it must stay available to the buyers"))
+			return
+		}
+
+		released, err := s.downloadableRepository.DeleteReleasingFile(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		// the document is gone, so a file that can't be deleted is only left behind in the storage
+		if released {
+			if err := s.storage.Delete(ctx, downloadable.Location); err != nil {
+				log.Printf("could not delete the file %s of the downloadable %s: %v", downloadable.Location, id, err)
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
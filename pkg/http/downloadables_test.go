@@ -6,13 +6,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/eikc/minicommerce/pkg/firestore"
 	"github.com/eikc/minicommerce/pkg/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -63,7 +66,7 @@ func TestGetDownloadables(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockRepo := mocks.NewMockDownloadableRepository(ctrl)
-			mockRepo.EXPECT().GetAll(gomock.Any()).Return(tC.data, tC.err).Times(1)
+			mockRepo.EXPECT().List(gomock.Any(), minicommerce.ListOptions{}).Return(&minicommerce.DownloadablePage{Downloadables: tC.data}, tC.err).Times(1)
 
 			server := Server{
 				downloadableRepository: mockRepo,
@@ -119,19 +122,29 @@ func TestCreateDownloadable(t *testing.T) {
 	storage := mocks.NewMockStorage(ctrl)
 
 	var capturedDownloadable struct {
-		Name     string
-		Location string
+		Name        string
+		Location    string
+		Hash        string
+		Size        int64
+		ContentType string
 	}
-	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, d *minicommerce.Downloadable) {
-		capturedDownloadable = struct {
-			Name     string
-			Location string
-		}{
-			Name:     d.Name,
-			Location: d.Location,
+	var written string
+	storage.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), &minicommerce.StorageMetadata{ContentType: "application/pdf"}).Do(func(ctx context.Context, location string, r io.Reader, metadata *minicommerce.StorageMetadata) {
+		written = location
+		ioutil.ReadAll(r)
+	}).Times(1)
+	repo.EXPECT().CreateSharingFile(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, d *minicommerce.Downloadable) {
+		if d.Location != written {
+			t.Errorf("expected the downloadable to be stored at %s, got %s", written, d.Location)
 		}
+
+		capturedDownloadable.Name = d.Name
+		// the ID is random, so it is replaced to keep the snapshot stable
+		capturedDownloadable.Location = strings.Replace(d.Location, d.ID, "{id}", 1)
+		capturedDownloadable.Hash = d.Hash
+		capturedDownloadable.Size = d.Size
+		capturedDownloadable.ContentType = d.ContentType
 	}).Times(1)
-	storage.EXPECT().Write(gomock.Any(), "simple.pdf", gomock.Any()).Times(1)
 
 	server := Server{
 		downloadableRepository: repo,
@@ -151,6 +164,56 @@ func TestCreateDownloadable(t *testing.T) {
 	cupaloy.SnapshotT(t, capturedDownloadable)
 }
 
+func TestCreateDownloadableDuplicate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockDownloadableRepository(ctrl)
+	storage := mocks.NewMockStorage(ctrl)
+
+	existing := &minicommerce.Downloadable{ID: "existing", Name: "simple.pdf", Location: "downloadables/existing/simple.pdf"}
+
+	var written string
+	storage.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), &minicommerce.StorageMetadata{ContentType: "application/pdf"}).Do(func(ctx context.Context, location string, r io.Reader, metadata *minicommerce.StorageMetadata) {
+		written = location
+		ioutil.ReadAll(r)
+	}).Times(1)
+	var created minicommerce.Downloadable
+	repo.EXPECT().CreateSharingFile(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, d *minicommerce.Downloadable) {
+		existing.Hash = d.Hash
+		d.Location = existing.Location
+		created = *d
+	}).Times(1).Return(nil)
+	storage.EXPECT().Delete(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, location string) {
+		if location != written {
+			t.Errorf("expected the duplicate at %s to be deleted, got %s", written, location)
+		}
+	}).Times(1).Return(nil)
+
+	server := Server{
+		downloadableRepository: repo,
+		storage:                storage,
+		router:                 httprouter.New(),
+	}
+	server.routes()
+
+	recorder := httptest.NewRecorder()
+	r, err := newfileUploadRequest("/api/downloadables", "file", "./testfiles/simple.pdf")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	if created.ID == existing.ID || created.Location != existing.Location || created.Hash != existing.Hash {
+		t.Errorf("expected a new downloadable sharing the file of %v, got %v", existing, created)
+	}
+}
+
 // Creates a new file upload http request with optional extra params
 func newfileUploadRequest(uri string, paramName, path string) (*http.Request, error) {
 	file, err := os.Open(path)
@@ -176,3 +239,124 @@ func newfileUploadRequest(uri string, paramName, path string) (*http.Request, er
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	return req, err
 }
+
+func TestDeleteDownloadable(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		query       string
+		invalid     bool
+		err         error
+		referenced  bool
+		checked     bool
+		bought      bool
+		sold        bool
+		deletes     bool
+		fileShared  bool
+		deletesFile bool
+	}{
+		{
+			desc:        "Deleting a downloadable no product embeds will delete it and its file",
+			checked:     true,
+			sold:        true,
+			deletes:     true,
+			deletesFile: true,
+		},
+		{
+			desc:       "When another downloadable has the same file, the file will be kept",
+			checked:    true,
+			sold:       true,
+			deletes:    true,
+			fileShared: true,
+		},
+		{
+			desc:    "When the downloadable has been bought, it will return 409",
+			checked: true,
+			sold:    true,
+			bought:  true,
+		},
+		{
+			desc:       "When the downloadable has been bought and force is given, it will return 409 anyway",
+			query:      "?force=true",
+			referenced: true,
+			sold:       true,
+			bought:     true,
+		},
+		{
+			desc:       "When products embed the downloadable, it will return 409",
+			checked:    true,
+			referenced: true,
+		},
+		{
+			desc:        "When products embed the downloadable and force is given, it will be deleted anyway",
+			query:       "?force=true",
+			referenced:  true,
+			sold:        true,
+			deletes:     true,
+			deletesFile: true,
+		},
+		{
+			desc:    "When force isn't a boolean, it will return 400",
+			query:   "?force=maybe",
+			invalid: true,
+		},
+		{
+			desc: "When no downloadable exists, it will return 404",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockDownloadableRepository(ctrl)
+			references := mocks.NewMockDownloadableReferenceChecker(ctrl)
+			downloads := mocks.NewMockDownloadService(ctrl)
+			storage := mocks.NewMockStorage(ctrl)
+
+			location := "downloadables/pdf/book.pdf"
+			if !tC.invalid {
+				repo.EXPECT().Get(gomock.Any(), "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Location: location}, tC.err)
+			}
+			if tC.checked {
+				references.EXPECT().DownloadableReferenced(gomock.Any(), "pdf").Times(1).Return(tC.referenced, nil)
+			}
+			if tC.sold {
+				downloads.EXPECT().Purchased(gomock.Any(), "pdf").Times(1).Return(tC.bought, nil)
+			}
+			if tC.deletes {
+				repo.EXPECT().DeleteReleasingFile(gomock.Any(), "pdf").Times(1).Return(!tC.fileShared, nil)
+			}
+			if tC.deletesFile {
+				storage.EXPECT().Delete(gomock.Any(), location).Times(1).Return(nil)
+			}
+
+			server := Server{
+				downloadableRepository:       repo,
+				downloadableReferenceChecker: references,
+				downloadService:              downloads,
+				storage:                      storage,
+				router:                       httprouter.New(),
+			}
+			server.routes()
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodDelete, "/api/downloadables/pdf"+tC.query, nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
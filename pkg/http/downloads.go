@@ -0,0 +1,248 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/downloads"
+	"github.com/julienschmidt/httprouter"
+)
+
+// downloadLinkTTL is the number of seconds a signed download link is valid for
+const downloadLinkTTL int64 = 60 * 60
+
+// TrustedProxies are the networks of the load balancers in front of the server, only they are trusted to tell
+// the address of the client in the X-Forwarded-For header
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a comma separated list of addresses and CIDR networks, an empty list trusts no proxy
+func ParseTrustedProxies(proxies string) (TrustedProxies, error) {
+	var trusted TrustedProxies
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("the trusted proxy %s is not an address or a network", proxy)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("the trusted proxy %s is not an address or a network", proxy)
+		}
+		trusted = append(trusted, network)
+	}
+
+	return trusted, nil
+}
+
+// contains reports whether the address belongs to a trusted proxy
+func (t TrustedProxies) contains(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) getOrderDownloads() httprouter.Handle {
+	type downloadItem struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		URL     string `json:"url"`
+		Expires int64  `json:"expires"`
+	}
+
+	type response struct {
+		Collection []downloadItem `json:"collection"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		downloadables, err := s.downloadService.Downloadables(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		expires := s.timeService.Now() + downloadLinkTTL
+		resp := response{
+			Collection: make([]downloadItem, 0),
+		}
+		for _, d := range downloadables {
+			query := url.Values{}
+			query.Set("expires", strconv.FormatInt(expires, 10))
+			query.Set("signature", s.downloadSigner.Sign(id, d.ID, expires))
+
+			resp.Collection = append(resp.Collection, downloadItem{
+				ID:      d.ID,
+				Name:    d.Name,
+				URL:     fmt.Sprintf("/api/downloads/%s/%s?%s", url.PathEscape(id), url.PathEscape(d.ID), query.Encode()),
+				Expires: expires,
+			})
+		}
+
+		sendJSON(w, http.StatusOK, resp)
+	}
+}
+
+// getDownload streams the file of a signed download link, the link is verified here instead of by the bucket
+// so the location of the file is never handed out. It serves HEAD requests, conditional requests and a single Range,
+// so interrupted downloads of large files can be resumed. A request that is refused is added to the access log
+func (s *Server) getDownload() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		orderID := params.ByName("orderId")
+		downloadableID := params.ByName("downloadableId")
+		query := r.URL.Query()
+
+		expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+		if err != nil || !s.downloadSigner.Verify(orderID, downloadableID, expires, query.Get("signature")) {
+			s.refuseDownload(w, r, orderID, downloadableID, &requestError{status: http.StatusForbidden, code: codeInvalidLink, message: "The download link is not valid"})
+			return
+		}
+
+		if expires < s.timeService.Now() {
+			s.refuseDownload(w, r, orderID, downloadableID, &requestError{status: http.StatusGone, code: codeLinkExpired, message: "The download link has expired, get a new one from the order"})
+			return
+		}
+
+		downloadable, attrs, err := s.downloadService.Stat(ctx, orderID, downloadableID)
+		if err != nil {
+			s.refuseDownload(w, r, orderID, downloadableID, err)
+			return
+		}
+
+		etag := storageETag(attrs)
+		if notModified(r, etag, attrs.ModTime) {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Last-Modified", httpTime(attrs.ModTime))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		rng, partial, err := requestedRange(r, etag, attrs)
+		if err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", attrs.Size))
+			sendError(w, &requestError{status: http.StatusRequestedRangeNotSatisfiable, code: codeRangeNotSatisfiable, message: "The range is outside of the file"})
+			return
+		}
+		if !partial {
+			rng = byteRange{start: 0, length: attrs.Size}
+		}
+
+		var content io.ReadCloser
+		if r.Method != http.MethodHead {
+			link := minicommerce.DownloadLink{Signature: query.Get("signature"), Expires: expires}
+			content, err = s.downloadService.Open(ctx, orderID, downloadableID, link, rng.start, s.downloadClient(r))
+			if err != nil {
+				s.refuseDownload(w, r, orderID, downloadableID, err)
+				return
+			}
+			defer content.Close()
+		}
+
+		contentType := attrs.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", downloadable.Name))
+		w.Header().Set("Content-Length", strconv.FormatInt(rng.length, 10))
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", httpTime(attrs.ModTime))
+
+		status := http.StatusOK
+		if partial {
+			w.Header().Set("Content-Range", rng.contentRange(attrs.Size))
+			status = http.StatusPartialContent
+		}
+		w.WriteHeader(status)
+
+		if content == nil {
+			return
+		}
+
+		// the status has been sent, so a failing copy can only be noticed by the client as a short body
+		if _, err := io.CopyN(w, content, rng.length); err != nil {
+			if _, ok := err.(*downloads.IntegrityError); ok {
+				log.Printf("download of order %s stopped: %v", orderID, err)
+			}
+		}
+	}
+}
+
+// refuseDownload adds the refused request to the access log and sends the error. The error is sent
+// even when it can't be logged, the client must not be served because the log is unavailable
+func (s *Server) refuseDownload(w http.ResponseWriter, r *http.Request, orderID, downloadableID string, err error) {
+	if logErr := s.downloadService.Refuse(r.Context(), orderID, downloadableID, err.Error(), s.downloadClient(r)); logErr != nil {
+		log.Printf("could not log the refused download of order %s: %v", orderID, logErr)
+	}
+
+	sendError(w, err)
+}
+
+// downloadClient is who sent the request, as it is recorded in the access log
+func (s *Server) downloadClient(r *http.Request) minicommerce.DownloadClient {
+	return minicommerce.DownloadClient{
+		IP:        s.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// clientIP is the address of the client. Behind a trusted proxy it is the last address in X-Forwarded-For that
+// isn't a trusted proxy, as the addresses before it are whatever the client sent. Without one the header is ignored
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !s.trustedProxies.contains(host) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header["X-Forwarded-For"], ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr == "" {
+			continue
+		}
+
+		if !s.trustedProxies.contains(addr) {
+			return addr
+		}
+		host = addr
+	}
+
+	return host
+}
@@ -0,0 +1,388 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/downloads"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+)
+
+func setupDownloadHTTPServer(t *testing.T) (*Server, *mocks.MockDownloadService, *mocks.MockTimeService, func()) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockDownloadService(ctrl)
+	time := mocks.NewMockTimeService(ctrl)
+
+	server := Server{
+		downloadService: service,
+		downloadSigner:  downloads.NewSigner(downloads.SigningSecret("testing-secret")),
+		timeService:     time,
+		router:          httprouter.New(),
+	}
+	server.routes()
+
+	return &server, service, time, func() {
+		ctrl.Finish()
+	}
+}
+
+func TestDownloads_GetOrderDownloads(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		downloadables []minicommerce.Downloadable
+	}{
+		{
+			desc: "The downloadables of the order are returned with a signed link",
+			downloadables: []minicommerce.Downloadable{
+				{ID: "pdf", Name: "book.pdf", Location: "book.pdf"},
+				{ID: "epub", Name: "book.epub", Location: "book.epub"},
+			},
+		},
+		{
+			desc:          "An order without digital products returns an empty collection",
+			downloadables: []minicommerce.Downloadable{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, service, time, finalize := setupDownloadHTTPServer(t)
+			defer finalize()
+
+			service.EXPECT().Downloadables(gomock.Any(), "order-one").Times(1).Return(tC.downloadables, nil)
+			time.EXPECT().Now().Times(1).Return(int64(1000))
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, "/api/orders/order-one/downloads", nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestDownloads_GetOrderDownloadsNotPaid(t *testing.T) {
+	server, service, _, finalize := setupDownloadHTTPServer(t)
+	defer finalize()
+
+	service.EXPECT().Downloadables(gomock.Any(), "order-one").Times(1).Return(nil, &downloads.OrderNotPaidError{})
+
+	recorder := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/api/orders/order-one/downloads", nil)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected the downloads of an unpaid order to be forbidden, got %d", recorder.Code)
+	}
+}
+
+func TestDownloads_GetDownload(t *testing.T) {
+	signer := downloads.NewSigner(downloads.SigningSecret("testing-secret"))
+	link := "/api/downloads/order-one/pdf?expires=2000&signature=" + signer.Sign("order-one", "pdf", 2000)
+	attrs := &minicommerce.StorageAttributes{Size: 8, ContentType: "application/pdf", ModTime: 1000, MD5: []byte{0xca, 0xfe}}
+	weak := &minicommerce.StorageAttributes{Size: 8, ModTime: 1000}
+
+	testCases := []struct {
+		desc    string
+		method  string
+		url     string
+		headers map[string]string
+		now     int64
+		attrs   *minicommerce.StorageAttributes
+		offset  int64
+		content string
+		refused string
+	}{
+		{
+			desc:    "A valid link streams the file as an attachment",
+			url:     link,
+			now:     1000,
+			attrs:   attrs,
+			content: "the book",
+		},
+		{
+			desc:    "A range streams the part of the file as partial content",
+			url:     link,
+			headers: map[string]string{"Range": "bytes=4-"},
+			now:     1000,
+			attrs:   attrs,
+			offset:  4,
+			content: "book",
+		},
+		{
+			desc:    "A suffix range streams the end of the file",
+			url:     link,
+			headers: map[string]string{"Range": "bytes=-3"},
+			now:     1000,
+			attrs:   attrs,
+			offset:  5,
+			content: "ook",
+		},
+		{
+			desc:    "A closed range only streams the bytes in it",
+			url:     link,
+			headers: map[string]string{"Range": "bytes=0-2"},
+			now:     1000,
+			attrs:   attrs,
+			content: "the book",
+		},
+		{
+			desc:    "A range with an If-Range that no longer matches streams the whole file",
+			url:     link,
+			headers: map[string]string{"Range": "bytes=4-", "If-Range": `"beef"`},
+			now:     1000,
+			attrs:   attrs,
+			content: "the book",
+		},
+		{
+			desc:    "A range with an If-Range of a weak ETag streams the whole file",
+			url:     link,
+			headers: map[string]string{"Range": "bytes=4-", "If-Range": `W/"3e8-8"`},
+			now:     1000,
+			attrs:   weak,
+			content: "the book",
+		},
+		{
+			desc:    "A range starting after the end of the file will return 416",
+			url:     link,
+			headers: map[string]string{"Range": "bytes=8-"},
+			now:     1000,
+			attrs:   attrs,
+		},
+		{
+			desc:    "A matching If-None-Match will return 304",
+			url:     link,
+			headers: map[string]string{"If-None-Match": `"cafe"`},
+			now:     1000,
+			attrs:   attrs,
+		},
+		{
+			desc:    "An If-Modified-Since at the modified time will return 304",
+			url:     link,
+			headers: map[string]string{"If-Modified-Since": "Thu, 01 Jan 1970 00:16:40 GMT"},
+			now:     1000,
+			attrs:   weak,
+		},
+		{
+			desc:   "A HEAD request returns the headers without opening the file",
+			method: http.MethodHead,
+			url:    link,
+			now:    1000,
+			attrs:  attrs,
+		},
+		{
+			desc:    "A link with a tampered expiry will return 403",
+			url:     "/api/downloads/order-one/pdf?expires=3000&signature=" + signer.Sign("order-one", "pdf", 2000),
+			refused: "The download link is not valid",
+		},
+		{
+			desc:    "A link for another downloadable will return 403",
+			url:     "/api/downloads/order-one/epub?expires=2000&signature=" + signer.Sign("order-one", "pdf", 2000),
+			refused: "The download link is not valid",
+		},
+		{
+			desc:    "An expired link will return 410",
+			url:     link,
+			now:     2001,
+			refused: "The download link has expired, get a new one from the order",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, service, time, finalize := setupDownloadHTTPServer(t)
+			defer finalize()
+
+			if tC.now != 0 {
+				time.EXPECT().Now().Times(1).Return(tC.now)
+			}
+
+			if tC.attrs != nil {
+				service.EXPECT().Stat(gomock.Any(), "order-one", "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf"}, tC.attrs, nil)
+			}
+
+			client := minicommerce.DownloadClient{IP: "203.0.113.7", UserAgent: "minicommerce-test"}
+			if tC.content != "" {
+				content := ioutil.NopCloser(strings.NewReader(tC.content))
+				service.EXPECT().Open(gomock.Any(), "order-one", "pdf", minicommerce.DownloadLink{Signature: signer.Sign("order-one", "pdf", 2000), Expires: 2000}, tC.offset, client).Times(1).Return(content, nil)
+			}
+
+			if tC.refused != "" {
+				service.EXPECT().Refuse(gomock.Any(), "order-one", gomock.Any(), tC.refused, client).Times(1).Return(nil)
+			}
+
+			method := tC.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(method, tC.url, nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+			r.RemoteAddr = "203.0.113.7:51234"
+			r.Header.Set("User-Agent", "minicommerce-test")
+			for k, v := range tC.headers {
+				r.Header.Set(k, v)
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status        int
+				contentLength string
+				contentRange  string
+				etag          string
+				disposition   string
+				body          string
+			}{
+				status:        recorder.Code,
+				contentLength: recorder.Header().Get("Content-Length"),
+				contentRange:  recorder.Header().Get("Content-Range"),
+				etag:          recorder.Header().Get("ETag"),
+				disposition:   recorder.Header().Get("Content-Disposition"),
+				body:          recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestDownloads_GetDownloadLimitReached(t *testing.T) {
+	server, service, time, finalize := setupDownloadHTTPServer(t)
+	defer finalize()
+
+	signer := downloads.NewSigner(downloads.SigningSecret("testing-secret"))
+	time.EXPECT().Now().Times(1).Return(int64(1000))
+	service.EXPECT().Stat(gomock.Any(), "order-one", "pdf").Times(1).Return(&minicommerce.Downloadable{ID: "pdf", Name: "book.pdf"}, &minicommerce.StorageAttributes{Size: 8}, nil)
+	limitErr := &minicommerce.DownloadLimitError{OrderID: "order-one", DownloadableID: "pdf"}
+	service.EXPECT().Open(gomock.Any(), "order-one", "pdf", minicommerce.DownloadLink{Signature: signer.Sign("order-one", "pdf", 2000), Expires: 2000}, int64(0), gomock.Any()).Times(1).Return(nil, limitErr)
+	service.EXPECT().Refuse(gomock.Any(), "order-one", "pdf", limitErr.Error(), gomock.Any()).Times(1).Return(nil)
+
+	recorder := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/api/downloads/order-one/pdf?expires=2000&signature="+signer.Sign("order-one", "pdf", 2000), nil)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	server.router.ServeHTTP(recorder, r)
+
+	resp := struct {
+		status int
+		body   string
+	}{
+		status: recorder.Code,
+		body:   recorder.Body.String(),
+	}
+
+	cupaloy.SnapshotT(t, resp)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{
+			desc:       "The remote address is used without its port",
+			remoteAddr: "203.0.113.7:51234",
+			expected:   "203.0.113.7",
+		},
+		{
+			desc:       "The forwarded addresses of a client that isn't a trusted proxy are ignored",
+			remoteAddr: "203.0.113.7:51234",
+			forwarded:  "198.51.100.1",
+			expected:   "203.0.113.7",
+		},
+		{
+			desc:       "Behind a trusted proxy the address it was forwarded for is used",
+			remoteAddr: "10.0.0.2:51234",
+			forwarded:  "198.51.100.1",
+			expected:   "198.51.100.1",
+		},
+		{
+			desc:       "Behind trusted proxies the last address that isn't one of them is used",
+			remoteAddr: "10.0.0.2:51234",
+			forwarded:  "203.0.113.99, 198.51.100.1, 192.0.2.1",
+			expected:   "198.51.100.1",
+		},
+		{
+			desc:       "A trusted proxy that forwards nothing is the client",
+			remoteAddr: "10.0.0.2:51234",
+			expected:   "10.0.0.2",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server := Server{trustedProxies: trusted}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tC.remoteAddr
+			if tC.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tC.forwarded)
+			}
+
+			if ip := server.clientIP(r); ip != tC.expected {
+				t.Errorf("expected the ip %s, got %s", tC.expected, ip)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		proxies  string
+		expected int
+		invalid  bool
+	}{
+		{desc: "An empty list trusts no proxy", proxies: ""},
+		{desc: "Addresses and networks are trusted", proxies: "10.0.0.0/8, 192.0.2.1, 2001:db8::1", expected: 3},
+		{desc: "Something that isn't an address is refused", proxies: "10.0.0.0/8, load-balancer", invalid: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			trusted, err := ParseTrustedProxies(tC.proxies)
+			if tC.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %v", trusted)
+				}
+				return
+			}
+
+			if err != nil || len(trusted) != tC.expected {
+				t.Errorf("expected %d trusted proxies, got %v and %v", tC.expected, trusted, err)
+			}
+		})
+	}
+}
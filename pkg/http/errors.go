@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/checkout"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/downloads"
+	"github.com/eikc/minicommerce/pkg/firestore"
+)
+
+// problemContentType is the media type of every error response, see RFC 7807
+const problemContentType = "application/problem+json"
+
+// The machine readable codes of the error responses, clients should switch on these instead of the detail
+const (
+	codeBadRequest          = "bad_request"
+	codeInvalidSignature    = "invalid_signature"
+	codeInvalidCursor       = "invalid_cursor"
+	codeInvalidLink         = "invalid_link"
+	codeLinkExpired         = "link_expired"
+	codeRangeNotSatisfiable = "range_not_satisfiable"
+	codeValidationFailed    = "validation_failed"
+	codeNotFound            = "not_found"
+	codeAlreadyExists       = "already_exists"
+	codeConflict            = "conflict"
+	codeEmptyCart           = "empty_cart"
+	codeAlreadyCheckedOut   = "already_checked_out"
+	codeProductUnavailable  = "product_unavailable"
+	codePaymentDeclined     = "payment_declined"
+	codeCouponRejected      = "coupon_rejected"
+	codeCouponExhausted     = "coupon_exhausted"
+	codeOrderNotPaid        = "order_not_paid"
+	codeNotPurchased        = "not_purchased"
+	codeDownloadLimit       = "download_limit_reached"
+	codeDownloadExpired     = "download_window_passed"
+	codeCorruptFile         = "corrupt_file"
+	codeInternal            = "internal_error"
+)
+
+// problem is the body of an error response
+type problem struct {
+	Type   string                    `json:"type"`
+	Title  string                    `json:"title"`
+	Status int                       `json:"status"`
+	Code   string                    `json:"code"`
+	Detail string                    `json:"detail,omitempty"`
+	Errors []minicommerce.FieldError `json:"errors,omitempty"`
+}
+
+// requestError is an error caused by the request itself, its message is meant for the client
+type requestError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// badRequest returns an error telling the client the request is malformed
+func badRequest(message string) error {
+	return &requestError{status: http.StatusBadRequest, code: codeBadRequest, message: message}
+}
+
+// conflict returns an error telling the client the request conflicts with the current state of a resource
+func conflict(message string) error {
+	return &requestError{status: http.StatusConflict, code: codeConflict, message: message}
+}
+
+// sendError sends err as a problem. This is the only place errors are mapped to status codes,
+// errors that are not known here are logged and sent as a 500 without details, so internals don't leak
+func sendError(w http.ResponseWriter, err error) error {
+	p := problemFor(err)
+	if p.Status == http.StatusInternalServerError {
+		log.Printf("internal error: %v", err)
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	_, err = w.Write(b)
+	return err
+}
+
+// problemFor maps err to the problem describing it
+func problemFor(err error) problem {
+	switch e := err.(type) {
+	case *requestError:
+		return newProblem(e.status, e.code, e.message)
+	case *minicommerce.ValidationError:
+		p := newProblem(http.StatusUnprocessableEntity, codeValidationFailed, "One or more fields are invalid")
+		p.Errors = e.Fields
+		return p
+	case *firestore.DocumentNotFoundError:
+		return newProblem(http.StatusNotFound, codeNotFound, "The requested resource does not exist")
+	case *firestore.DocumentExistsError:
+		return newProblem(http.StatusConflict, codeAlreadyExists, "The resource already exists")
+	case *firestore.InvalidCursorError:
+		return newProblem(http.StatusBadRequest, codeInvalidCursor, "The cursor is not valid, start over from the first page")
+	case *checkout.EmptyCartError:
+		return newProblem(http.StatusBadRequest, codeEmptyCart, e.Error())
+	case *minicommerce.AlreadyCheckedOutError:
+		return newProblem(http.StatusConflict, codeAlreadyCheckedOut, e.Error())
+	case *checkout.ProductUnavailableError:
+		return newProblem(http.StatusConflict, codeProductUnavailable, e.Error())
+	case *minicommerce.PaymentDeclinedError:
+		return newProblem(http.StatusPaymentRequired, codePaymentDeclined, e.Error())
+	case *coupons.RejectionError:
+		return newProblem(http.StatusUnprocessableEntity, codeCouponRejected, e.Error())
+	case *minicommerce.CouponExhaustedError:
+		return newProblem(http.StatusUnprocessableEntity, codeCouponExhausted, e.Error())
+	case *downloads.OrderNotPaidError:
+		return newProblem(http.StatusForbidden, codeOrderNotPaid, e.Error())
+	case *downloads.NotPurchasedError:
+		return newProblem(http.StatusForbidden, codeNotPurchased, e.Error())
+	case *minicommerce.DownloadLimitError:
+		if e.Expired {
+			return newProblem(http.StatusForbidden, codeDownloadExpired, e.Error())
+		}
+		return newProblem(http.StatusForbidden, codeDownloadLimit, e.Error())
+	case *downloads.IntegrityError:
+		return newProblem(http.StatusInternalServerError, codeCorruptFile, "The file is damaged and can't be downloaded right now")
+	default:
+		return newProblem(http.StatusInternalServerError, codeInternal, "An unexpected error occurred")
+	}
+}
+
+func newProblem(status int, code, detail string) problem {
+	return problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/firestore"
+)
+
+func TestSendError(t *testing.T) {
+	testCases := []struct {
+		desc string
+		err  error
+	}{
+		{
+			desc: "A request error will be sent with its own status and message",
+			err:  badRequest("The request body is not valid JSON"),
+		},
+		{
+			desc: "A validation error will be sent as 422 with the invalid fields",
+			err: &minicommerce.ValidationError{Fields: []minicommerce.FieldError{
+				{Field: "price", Message: "can not be negative"},
+			}},
+		},
+		{
+			desc: "A missing document will be sent as 404 without the path of the document",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+		{
+			desc: "An existing document will be sent as 409",
+			err:  &firestore.DocumentExistsError{},
+		},
+		{
+			desc: "A rejected coupon will be sent as 422",
+			err:  &coupons.RejectionError{Code: "EXPIRED", Reason: coupons.ReasonExpired},
+		},
+		{
+			desc: "An order that is already checked out will be sent as 409",
+			err:  &minicommerce.AlreadyCheckedOutError{},
+		},
+		{
+			desc: "An unknown error will be sent as 500 without leaking the message",
+			err:  errors.New("rpc error: code = Unavailable desc = some internal detail"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if err := sendError(recorder, tC.err); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if contentType := recorder.Header().Get("Content-Type"); contentType != problemContentType {
+				t.Errorf("expected the content type to be %s, got %s", problemContentType, contentType)
+			}
+
+			result := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, result)
+		})
+	}
+}
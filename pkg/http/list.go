@@ -0,0 +1,80 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/eikc/minicommerce"
+)
+
+// maxLimit is the largest page a client can ask for
+const maxLimit = 100
+
+// links points to the current and the next page of a collection, next is left out on the last page
+type links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+}
+
+// listOptions reads the limit, cursor and order query parameters of a collection request
+func listOptions(query url.Values) (minicommerce.ListOptions, error) {
+	opts := minicommerce.ListOptions{
+		Cursor: query.Get("cursor"),
+		Order:  minicommerce.SortOrder(query.Get("order")),
+	}
+
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return opts, badRequest(fmt.Sprintf("The limit must be a number between 1 and %d", maxLimit))
+		}
+		opts.Limit = limit
+	}
+
+	switch opts.Order {
+	case "", minicommerce.SortAscending, minicommerce.SortDescending:
+	default:
+		return opts, badRequest("The order must be either asc or desc")
+	}
+
+	return opts, nil
+}
+
+// productFilter reads the active and type query parameters of a products request
+func productFilter(query url.Values) (minicommerce.ProductFilter, error) {
+	var filter minicommerce.ProductFilter
+
+	if a := query.Get("active"); a != "" {
+		active, err := strconv.ParseBool(a)
+		if err != nil {
+			return filter, badRequest("The active filter must be either true or false")
+		}
+		filter.Active = &active
+	}
+
+	filter.Type = minicommerce.ProductType(query.Get("type"))
+	switch filter.Type {
+	case "", minicommerce.ProductTypeDigital, minicommerce.ProductTypeLink, minicommerce.ProductTypeShippable:
+	default:
+		return filter, badRequest("The type filter must be one of digital, linkable or shippable")
+	}
+
+	return filter, nil
+}
+
+// pageLinks links to the requested page and, when there is a cursor, the page after it with the same query
+func pageLinks(r *http.Request, cursor string) links {
+	l := links{
+		Self: r.URL.RequestURI(),
+	}
+
+	if cursor != "" {
+		query := r.URL.Query()
+		query.Set("cursor", cursor)
+		l.Next = r.URL.Path + "?" + query.Encode()
+	}
+
+	return l
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/pricing"
+	"github.com/julienschmidt/httprouter"
+)
+
+// orderRequest is what a client can set on an order, the payment and the refund are only ever set by the checkout
+// and the payment processor, and the totals are calculated from the items
+type orderRequest struct {
+	Order struct {
+		Coupon   string                `json:"coupon"`
+		Customer minicommerce.Customer `json:"customer"`
+		Items    []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	} `json:"order"`
+}
+
+func (s *Server) getAllOrders() httprouter.Handle {
+	type response struct {
+		Collection []minicommerce.Order `json:"collection"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		ctx := r.Context()
+
+		orders, err := s.orderRepository.GetAll(ctx)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		resp := response{
+			Collection: make([]minicommerce.Order, 0),
+		}
+		resp.Collection = append(resp.Collection, orders...)
+
+		sendJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) getOrderByID() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		order, err := s.orderRepository.Get(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, order)
+	}
+}
+
+func (s *Server) postOrder() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		ctx := r.Context()
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request orderRequest
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		id, err := s.idGenerator.New()
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		items, err := s.orderItems(ctx, request)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		order := minicommerce.Order{
+			ID:       id,
+			Coupon:   request.Order.Coupon,
+			Customer: request.Order.Customer,
+			Items:    items,
+		}
+
+		if err := s.calculateOrder(ctx, &order); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if err := s.orderRepository.Create(ctx, &order); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, order)
+	}
+}
+
+func (s *Server) putOrder() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request orderRequest
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		order, err := s.orderRepository.Get(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if order.PaymentID != "" {
+			sendError(w, &minicommerce.AlreadyCheckedOutError{OrderID: order.ID})
+			return
+		}
+
+		items, err := s.orderItems(ctx, request)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		order.Coupon = request.Order.Coupon
+		order.Customer = request.Order.Customer
+		order.Items = items
+
+		if err := s.calculateOrder(ctx, order); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if err := s.orderEditor.Edit(ctx, order); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, order)
+	}
+}
+
+// orderItems looks up the products referenced by an order request, so the order
+// always embeds the product as it looked when it was added. Products that don't exist are invalid fields
+func (s *Server) orderItems(ctx context.Context, request orderRequest) ([]minicommerce.Product, error) {
+	var items []minicommerce.Product
+	var fields []minicommerce.FieldError
+	for n, i := range request.Order.Items {
+		// this can be optimized by using firestore getAll Document refs
+		product, err := s.productRepository.Get(ctx, i.ID)
+		if _, ok := err.(*firestore.DocumentNotFoundError); ok {
+			fields = append(fields, minicommerce.FieldError{Field: fmt.Sprintf("items[%d].id", n), Message: "does not exist"})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, *product)
+	}
+
+	if len(fields) > 0 {
+		return nil, &minicommerce.ValidationError{Fields: fields}
+	}
+
+	return items, nil
+}
+
+// calculateOrder calculates the totals of the order from its items and its coupon, a coupon that doesn't exist
+// or can't be redeemed on the order is an invalid field
+func (s *Server) calculateOrder(ctx context.Context, order *minicommerce.Order) error {
+	var coupon *minicommerce.Coupon
+	if order.Coupon != "" {
+		var err error
+		coupon, err = s.couponValidator.Validate(ctx, order.Coupon, order)
+		if e, ok := err.(*coupons.RejectionError); ok {
+			message := fmt.Sprintf("can not be redeemed: %s", e.Reason)
+			if e.Reason == coupons.ReasonNotFound {
+				message = "does not exist"
+			}
+			return &minicommerce.ValidationError{Fields: []minicommerce.FieldError{{Field: "coupon", Message: message}}}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	pricing.Calculate(order, coupon, s.rules)
+	return nil
+}
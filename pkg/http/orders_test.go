@@ -0,0 +1,430 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/coupons"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/eikc/minicommerce/pkg/pricing"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+)
+
+func setupOrderHTTPServer(t *testing.T) (*Server, *mocks.MockOrderRepository,
+	*mocks.MockOrderEditor,
+	*mocks.MockProductRepository,
+	*mocks.MockCouponValidator,
+	*mocks.MockIDGenerator,
+	func()) {
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockOrderRepository(ctrl)
+	editor := mocks.NewMockOrderEditor(ctrl)
+	pRepo := mocks.NewMockProductRepository(ctrl)
+	validator := mocks.NewMockCouponValidator(ctrl)
+	uuidGenerator := mocks.NewMockIDGenerator(ctrl)
+
+	server := Server{
+		orderRepository:   repo,
+		orderEditor:       editor,
+		productRepository: pRepo,
+		couponValidator:   validator,
+		idGenerator:       uuidGenerator,
+		rules:             pricing.DefaultRules(),
+		router:            httprouter.New(),
+	}
+
+	server.routes()
+
+	return &server, repo, editor, pRepo, validator, uuidGenerator, func() {
+		ctrl.Finish()
+	}
+}
+
+func TestOrders_GetAllOrders(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		orders []minicommerce.Order
+		err    error
+	}{
+		{
+			desc: "Get all will return the collection of orders",
+			orders: []minicommerce.Order{
+				{
+					ID:        "order-one",
+					PaymentID: "payment-one",
+					Items: []minicommerce.Product{
+						{
+							ID:    "product-one",
+							Type:  minicommerce.ProductTypeDigital,
+							Name:  "Test product one",
+							Price: 15000,
+						},
+					},
+					Customer: minicommerce.Customer{
+						Name:  "testing name",
+						Email: "testing email",
+					},
+					Amount:    15000,
+					NetAmount: 15000,
+					Taxes:     3750,
+					Total:     18750,
+				},
+				{
+					ID: "order-two",
+				},
+			},
+		},
+		{
+			desc:   "When no orders exist, it will return an empty array as response",
+			orders: nil,
+		},
+		{
+			desc: "When the repository fails, we return an http 500",
+			err:  errors.New("some test error occurred"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, _, _, finalize := setupOrderHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().GetAll(gomock.Any()).Times(1).Return(tC.orders, tC.err)
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, "/api/orders", nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestOrders_GetOrderByID(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		id    string
+		order *minicommerce.Order
+		err   error
+	}{
+		{
+			desc: "Getting an order by ID will return the correct order",
+			id:   "order-one",
+			order: &minicommerce.Order{
+				ID:     "order-one",
+				Coupon: "testing-coupon",
+				Items: []minicommerce.Product{
+					{
+						ID:    "product-one",
+						Type:  minicommerce.ProductTypeShippable,
+						Name:  "Test product one",
+						Price: 15000,
+					},
+				},
+				Amount:    15000,
+				Shipping:  5000,
+				NetAmount: 20000,
+				Taxes:     5000,
+				Total:     25000,
+			},
+		},
+		{
+			desc:  "When no order exists, it will return 404",
+			id:    "does-not-exist",
+			order: nil,
+			err:   &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, _, _, finalize := setupOrderHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().Get(gomock.Any(), tC.id).Times(1).Return(tC.order, tC.err)
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/orders/%s", tC.id), nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestOrders_PostOrder(t *testing.T) {
+	type item struct {
+		ID string `json:"id"`
+	}
+
+	type order struct {
+		Coupon    string                `json:"coupon"`
+		Customer  minicommerce.Customer `json:"customer"`
+		Items     []item                `json:"items"`
+		Refunded  bool                  `json:"refunded"`
+		PaymentID string                `json:"paymentId"`
+		Total     int64                 `json:"total"`
+	}
+
+	type request struct {
+		Order order `json:"order"`
+	}
+
+	testCases := []struct {
+		desc       string
+		request    request
+		productErr error
+		couponErr  error
+		creates    bool
+	}{
+		{
+			desc: "Post order will embed the products and create the order",
+			request: request{
+				Order: order{
+					Coupon: "testing-coupon",
+					Customer: minicommerce.Customer{
+						Name:    "testing name",
+						Email:   "testing email",
+						Address: "testing address",
+						ZipCode: "testing zip code",
+						Phone:   "testing phone",
+					},
+					Items: []item{
+						{ID: "product-one"},
+					},
+				},
+			},
+			creates: true,
+		},
+		{
+			desc: "The payment, the refund and the totals of the request are ignored",
+			request: request{
+				Order: order{
+					Items: []item{
+						{ID: "product-one"},
+					},
+					Refunded:  true,
+					PaymentID: "payment-one",
+					Total:     1,
+				},
+			},
+			creates: true,
+		},
+		{
+			desc: "If a product does not exist, it will return 422 with the invalid fields",
+			request: request{
+				Order: order{
+					Items: []item{
+						{ID: "does-not-exist"},
+					},
+				},
+			},
+			productErr: &firestore.DocumentNotFoundError{},
+		},
+		{
+			desc: "If the coupon does not exist, it will return 422 with the invalid fields",
+			request: request{
+				Order: order{
+					Coupon: "does-not-exist",
+					Items: []item{
+						{ID: "product-one"},
+					},
+				},
+			},
+			couponErr: &coupons.RejectionError{Code: "does-not-exist", Reason: coupons.ReasonNotFound},
+		},
+		{
+			desc: "If the coupon can not be redeemed on the order, it will return 422 with the invalid fields",
+			request: request{
+				Order: order{
+					Coupon: "minimum-amount",
+					Items: []item{
+						{ID: "product-one"},
+					},
+				},
+			},
+			couponErr: &coupons.RejectionError{Code: "minimum-amount", Reason: coupons.ReasonMinimumAmount},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, productRepo, validator, idgenerator, finalize := setupOrderHTTPServer(t)
+			defer finalize()
+
+			idgenerator.EXPECT().New().Times(1).Return("123-321-123-321", nil)
+
+			for _, i := range tC.request.Order.Items {
+				product := minicommerce.Product{
+					ID:    i.ID,
+					Type:  minicommerce.ProductTypeDigital,
+					Name:  i.ID,
+					Price: 15000,
+				}
+
+				productRepo.EXPECT().Get(gomock.Any(), i.ID).Times(1).Return(&product, tC.productErr)
+			}
+
+			if code := tC.request.Order.Coupon; code != "" {
+				validator.EXPECT().Validate(gomock.Any(), code, gomock.Any()).Times(1).Return(&minicommerce.Coupon{ID: code, Active: true, PercentOff: 10}, tC.couponErr)
+			}
+
+			var captured minicommerce.Order
+			if tC.creates {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, o *minicommerce.Order) {
+					captured = *o
+				}).Times(1).Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			requestByte, _ := json.Marshal(tC.request)
+			r, err := http.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader(requestByte))
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			result := struct {
+				code     int
+				body     string
+				captured minicommerce.Order
+			}{
+				code:     recorder.Code,
+				body:     recorder.Body.String(),
+				captured: captured,
+			}
+
+			cupaloy.SnapshotT(t, result)
+		})
+	}
+}
+
+func TestOrders_PutOrder(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		id       string
+		existing *minicommerce.Order
+		err      error
+		saved    bool
+		editErr  error
+	}{
+		{
+			desc: "Put order will replace the customer and items and recalculate the totals, but not take the payment from the request",
+			id:   "order-one",
+			existing: &minicommerce.Order{
+				ID: "order-one",
+				Customer: minicommerce.Customer{
+					Name: "old name",
+				},
+				Amount: 15000,
+				Total:  18750,
+			},
+			saved: true,
+		},
+		{
+			desc: "When the order has been checked out, it will return 409",
+			id:   "order-one",
+			existing: &minicommerce.Order{
+				ID:        "order-one",
+				PaymentID: "payment-one",
+				Amount:    15000,
+				Total:     18750,
+			},
+		},
+		{
+			desc: "When the order is checked out while it is put, it will return 409",
+			id:   "order-one",
+			existing: &minicommerce.Order{
+				ID:     "order-one",
+				Amount: 15000,
+				Total:  18750,
+			},
+			saved:   true,
+			editErr: &minicommerce.AlreadyCheckedOutError{OrderID: "order-one"},
+		},
+		{
+			desc: "When no order exists, it will return 404",
+			id:   "does-not-exist",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, editor, productRepo, _, _, finalize := setupOrderHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().Get(gomock.Any(), tC.id).Times(1).Return(tC.existing, tC.err)
+
+			var captured minicommerce.Order
+			if tC.saved {
+				product := minicommerce.Product{
+					ID:    "product-one",
+					Type:  minicommerce.ProductTypeLink,
+					Name:  "product-one",
+					Price: 5000,
+					URL:   "https://some-url",
+				}
+
+				productRepo.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&product, nil)
+				editor.EXPECT().Edit(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, o *minicommerce.Order) {
+					captured = *o
+				}).Times(1).Return(tC.editErr)
+			}
+
+			body := `{"order":{"customer":{"name":"new name"},"items":[{"id":"product-one"}],"refunded":false,"paymentId":"payment-two","total":1}}`
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/orders/%s", tC.id), bytes.NewReader([]byte(body)))
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			result := struct {
+				code     int
+				body     string
+				captured minicommerce.Order
+			}{
+				code:     recorder.Code,
+				body:     recorder.Body.String(),
+				captured: captured,
+			}
+
+			cupaloy.SnapshotT(t, result)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/julienschmidt/httprouter"
+)
+
+// WebhookSecret is the shared secret the payment processor signs webhooks with
+type WebhookSecret string
+
+// signatureHeader is the header holding the hex encoded HMAC-SHA256 signature of the webhook body
+const signatureHeader = "X-Webhook-Signature"
+
+// maxWebhookSize is the largest webhook body that is read. Anyone can call the webhook and the body is read
+// before the signature is checked, so it is capped well above the size of any event from the processor
+const maxWebhookSize = 64 << 10
+
+func (s *Server) postPaymentWebhook() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		ctx := r.Context()
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+		defer r.Body.Close()
+		if err != nil {
+			sendError(w, badRequest("The request body could not be read, it can be at most 64 KB"))
+			return
+		}
+
+		if !s.validSignature(body, r.Header.Get(signatureHeader)) {
+			sendError(w, &requestError{status: http.StatusUnauthorized, code: codeInvalidSignature, message: "The signature of the webhook is invalid"})
+			return
+		}
+
+		var event minicommerce.PaymentEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		if event.ID == "" || event.ExternalID == "" {
+			sendError(w, badRequest("The event must have an id and an externalId"))
+			return
+		}
+
+		_, err = s.paymentEventRepository.Get(ctx, event.ID)
+		if err == nil {
+			// the event has already been processed, retries from the processor are acknowledged without doing anything
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if _, ok := err.(*firestore.DocumentNotFoundError); !ok {
+			sendError(w, err)
+			return
+		}
+
+		if err := s.applyPaymentEvent(ctx, event); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		event.Received = s.timeService.Now()
+		if err := s.paymentEventRepository.Create(ctx, &event); err != nil {
+			// a concurrent delivery of the same event won the race, the state it applied is the same as ours
+			if _, ok := err.(*firestore.DocumentExistsError); !ok {
+				sendError(w, err)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) validSignature(body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(s.webhookSecret) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.webhookSecret))
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// applyPaymentEvent updates the payment and the order it belongs to with the state from the event.
+// The event carries the absolute state of the payment, so applying it twice gives the same result
+func (s *Server) applyPaymentEvent(ctx context.Context, event minicommerce.PaymentEvent) error {
+	payment, err := s.paymentRepository.Modify(ctx, event.ExternalID, func(payment *minicommerce.Payment) bool {
+		switch event.Type {
+		case minicommerce.PaymentEventCaptured:
+			payment.Paid = true
+		case minicommerce.PaymentEventRefunded:
+			if event.RefundedAmount > payment.RefundedAmount {
+				payment.RefundedAmount = event.RefundedAmount
+			}
+			payment.Refunded = payment.RefundedAmount >= payment.Amount
+		default:
+			// events we don't care about are acknowledged so the processor stops sending them
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if !payment.Refunded || payment.OrderID == "" {
+		return nil
+	}
+
+	// the order is marked as refunded and gives the redemption of its coupon back at once,
+	// so a retried or concurrent delivery of the event never gives it back twice
+	return s.orderRefunder.Refund(ctx, payment.OrderID)
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/firestore"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+)
+
+const testWebhookSecret = "testing-secret"
+
+func setupPaymentHTTPServer(t *testing.T) (*Server, *mocks.MockPaymentRepository,
+	*mocks.MockPaymentEventRepository,
+	*mocks.MockOrderRefunder,
+	*mocks.MockTimeService,
+	func()) {
+
+	ctrl := gomock.NewController(t)
+	payments := mocks.NewMockPaymentRepository(ctrl)
+	events := mocks.NewMockPaymentEventRepository(ctrl)
+	refunder := mocks.NewMockOrderRefunder(ctrl)
+	time := mocks.NewMockTimeService(ctrl)
+
+	server := Server{
+		paymentRepository:      payments,
+		paymentEventRepository: events,
+		orderRefunder:          refunder,
+		timeService:            time,
+		webhookSecret:          testWebhookSecret,
+		router:                 httprouter.New(),
+	}
+
+	server.routes()
+
+	return &server, payments, events, refunder, time, func() {
+		ctrl.Finish()
+	}
+}
+
+func newWebhookRequest(body, secret string) (*http.Request, error) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+
+	r, err := http.NewRequest(http.MethodPost, "/api/payments/webhook", bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Set(signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+	return r, nil
+}
+
+// modifyPayment makes the repository apply the modification to the stored payment, and captures the saved payment
+// when the modification changed it
+func modifyPayment(stored *minicommerce.Payment, saved *minicommerce.Payment) func(context.Context, string, func(*minicommerce.Payment) bool) (*minicommerce.Payment, error) {
+	return func(ctx context.Context, externalID string, modify func(*minicommerce.Payment) bool) (*minicommerce.Payment, error) {
+		payment := *stored
+		if modify(&payment) && saved != nil {
+			*saved = payment
+		}
+
+		return &payment, nil
+	}
+}
+
+func TestPaymentWebhook_Signature(t *testing.T) {
+	server, _, _, _, _, finalize := setupPaymentHTTPServer(t)
+	defer finalize()
+
+	body := `{"id":"event-one","type":"payment.captured","externalId":"external-one"}`
+	r, err := newWebhookRequest(body, "some-other-secret")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected an invalid signature to be rejected, got %d", recorder.Code)
+	}
+}
+
+func TestPaymentWebhook_TooLarge(t *testing.T) {
+	server, _, _, _, _, finalize := setupPaymentHTTPServer(t)
+	defer finalize()
+
+	body := `{"id":"event-one","type":"payment.captured","externalId":"` + strings.Repeat("a", maxWebhookSize) + `"}`
+	r, err := newWebhookRequest(body, testWebhookSecret)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected a body above the limit to be rejected, got %d", recorder.Code)
+	}
+}
+
+func TestPaymentWebhook_Duplicate(t *testing.T) {
+	server, _, events, _, _, finalize := setupPaymentHTTPServer(t)
+	defer finalize()
+
+	events.EXPECT().Get(gomock.Any(), "event-one").Times(1).Return(&minicommerce.PaymentEvent{ID: "event-one"}, nil)
+
+	body := `{"id":"event-one","type":"payment.refunded","externalId":"external-one","refundedAmount":12500}`
+	r, err := newWebhookRequest(body, testWebhookSecret)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected a processed event to be acknowledged, got %d", recorder.Code)
+	}
+}
+
+func TestPaymentWebhook_Events(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		body     string
+		payment  *minicommerce.Payment
+		refunded bool
+	}{
+		{
+			desc:    "A captured event will mark the payment as paid",
+			body:    `{"id":"event-one","type":"payment.captured","externalId":"external-one"}`,
+			payment: &minicommerce.Payment{ID: "payment-one", OrderID: "order-one", ExternalID: "external-one", Amount: 12500},
+		},
+		{
+			desc:    "A partial refund will not mark the payment or the order as refunded",
+			body:    `{"id":"event-two","type":"payment.refunded","externalId":"external-one","refundedAmount":2500}`,
+			payment: &minicommerce.Payment{ID: "payment-one", OrderID: "order-one", ExternalID: "external-one", Amount: 12500, Paid: true},
+		},
+		{
+			desc:     "A full refund will mark both the payment and the order as refunded",
+			body:     `{"id":"event-three","type":"payment.refunded","externalId":"external-one","refundedAmount":12500}`,
+			payment:  &minicommerce.Payment{ID: "payment-one", OrderID: "order-one", ExternalID: "external-one", Amount: 12500, Paid: true, RefundedAmount: 2500},
+			refunded: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, payments, events, refunder, time, finalize := setupPaymentHTTPServer(t)
+			defer finalize()
+
+			events.EXPECT().Get(gomock.Any(), gomock.Any()).Times(1).Return(nil, &firestore.DocumentNotFoundError{})
+
+			var payment minicommerce.Payment
+			payments.EXPECT().Modify(gomock.Any(), "external-one", gomock.Any()).DoAndReturn(modifyPayment(tC.payment, &payment)).Times(1)
+
+			if tC.refunded {
+				refunder.EXPECT().Refund(gomock.Any(), "order-one").Times(1).Return(nil)
+			}
+
+			var event minicommerce.PaymentEvent
+			time.EXPECT().Now().Times(1).Return(int64(123321))
+			events.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, e *minicommerce.PaymentEvent) {
+				event = *e
+			}).Times(1).Return(nil)
+
+			r, err := newWebhookRequest(tC.body, testWebhookSecret)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, r)
+
+			result := struct {
+				code    int
+				payment minicommerce.Payment
+				event   minicommerce.PaymentEvent
+			}{
+				code:    recorder.Code,
+				payment: payment,
+				event:   event,
+			}
+
+			cupaloy.SnapshotT(t, result)
+		})
+	}
+}
+
+func TestPaymentWebhook_RefundFailed(t *testing.T) {
+	server, payments, events, refunder, _, finalize := setupPaymentHTTPServer(t)
+	defer finalize()
+
+	events.EXPECT().Get(gomock.Any(), "event-one").Times(1).Return(nil, &firestore.DocumentNotFoundError{})
+	paid := &minicommerce.Payment{ID: "payment-one", OrderID: "order-one", ExternalID: "external-one", Amount: 12500, Paid: true}
+	payments.EXPECT().Modify(gomock.Any(), "external-one", gomock.Any()).DoAndReturn(modifyPayment(paid, nil)).Times(1)
+	refunder.EXPECT().Refund(gomock.Any(), "order-one").Times(1).Return(errors.New("firestore is unavailable"))
+
+	// the event is not recorded, so the retry from the processor refunds the order again
+	body := `{"id":"event-one","type":"payment.refunded","externalId":"external-one","refundedAmount":12500}`
+	r, err := newWebhookRequest(body, testWebhookSecret)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected a failed refund to be retried, got %d", recorder.Code)
+	}
+}
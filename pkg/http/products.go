@@ -1,32 +1,53 @@
 package http
 
 import (
+	"context"
 	"net/http"
 
-	"github.com/eikc/minicommerce/pkg/firestore"
-
 	"github.com/eikc/minicommerce"
 
 	"github.com/julienschmidt/httprouter"
 )
 
+// downloadableReference is how a request refers to a downloadable of a product
+type downloadableReference struct {
+	ID string `json:"id"`
+}
+
 func (s *Server) getAllProducts() httprouter.Handle {
 
 	type response struct {
 		Collection []minicommerce.Product `json:"collection"`
+		Links      links                  `json:"links"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		ctx := r.Context()
-		products, err := s.productRepository.GetAll(ctx)
+		query := r.URL.Query()
+
+		opts, err := listOptions(query)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		filter, err := productFilter(query)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		page, err := s.productRepository.List(ctx, filter, opts)
 		if err != nil {
-			http.Error(w, err.Error(), 500)
+			sendError(w, err)
 			return
 		}
 
 		resp := response{
-			Collection: products,
+			Collection: make([]minicommerce.Product, 0),
+			Links:      pageLinks(r, page.NextCursor),
 		}
+		resp.Collection = append(resp.Collection, page.Products...)
 
 		sendJSON(w, 200, resp)
 	}
@@ -38,15 +59,8 @@ func (s *Server) getProductByID() httprouter.Handle {
 		id := params.ByName("id")
 		product, err := s.productRepository.Get(ctx, id)
 		if err != nil {
-			switch err.(type) {
-			case *firestore.DocumentNotFoundError:
-				http.Error(w, "product not found", 404)
-				return
-			default:
-				http.Error(w, err.Error(), 500)
-				return
-			}
-
+			sendError(w, err)
+			return
 		}
 
 		sendJSON(w, 200, product)
@@ -62,9 +76,7 @@ func (s *Server) postProduct() httprouter.Handle {
 			Price         int64                    `json:"price"`
 			Active        bool                     `json:"active"`
 			URL           string                   `json:"url"`
-			Downloadables []struct {
-				ID string `json:"id"`
-			} `json:"downloadables"`
+			Downloadables []downloadableReference  `json:"downloadables"`
 		} `json:"product"`
 	}
 
@@ -73,17 +85,18 @@ func (s *Server) postProduct() httprouter.Handle {
 		id, err := s.idGenerator.New()
 
 		if r.Body == nil {
-			http.Error(w, "Incorrect request", http.StatusBadRequest)
+			sendError(w, badRequest("The request body is missing"))
+			return
 		}
 
 		if err != nil {
-			http.Error(w, err.Error(), 500)
+			sendError(w, err)
 			return
 		}
 
 		var request request
 		if err := receiveJSON(r.Body, &request); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			sendError(w, badRequest("The request body is not valid JSON"))
 			return
 		}
 
@@ -101,26 +114,241 @@ func (s *Server) postProduct() httprouter.Handle {
 			URL:         request.Product.URL,
 		}
 
-		var downloadables []minicommerce.Downloadable
-		for _, d := range request.Product.Downloadables {
-			// this can be optimized by using firestore getAll Document refs
-			downloadable, err := s.downloadableRepository.Get(ctx, d.ID)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusNotFound)
-				return
-			}
-			downloadables = append(downloadables, *downloadable)
+		downloadables, err := s.productDownloadables(ctx, request.Product.Downloadables)
+		if err != nil {
+			sendError(w, err)
+			return
 		}
 
 		product.Downloadable = downloadables
 
-		// some product validation would be nice to make sure we don't save anything stupid..
-		// it's on the todo list
+		if err := product.Validate(); err != nil {
+			sendError(w, err)
+			return
+		}
+
 		if err := s.productRepository.Create(ctx, &product); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			sendError(w, err)
 			return
 		}
 
 		sendJSON(w, 200, product)
 	}
 }
+
+func (s *Server) putProduct() httprouter.Handle {
+	type request struct {
+		Product struct {
+			Type          minicommerce.ProductType `json:"type"`
+			Name          string                   `json:"name"`
+			Description   string                   `json:"description"`
+			Price         int64                    `json:"price"`
+			Active        bool                     `json:"active"`
+			URL           string                   `json:"url"`
+			Downloadables []downloadableReference  `json:"downloadables"`
+		} `json:"product"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request request
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		product, err := s.productRepository.Get(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		downloadables, err := s.productDownloadables(ctx, request.Product.Downloadables)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		product.Type = request.Product.Type
+		product.Name = request.Product.Name
+		product.Description = request.Product.Description
+		product.Price = request.Product.Price
+		product.Active = request.Product.Active
+		product.URL = request.Product.URL
+		product.Downloadable = downloadables
+		product.Updated = s.timeService.Now()
+
+		if err := product.Validate(); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if err := s.productRepository.Update(ctx, product); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, product)
+	}
+}
+
+func (s *Server) patchProduct() httprouter.Handle {
+	// only the fields present in the request are changed, so all of them are pointers
+	type request struct {
+		Product struct {
+			Type          *minicommerce.ProductType `json:"type"`
+			Name          *string                   `json:"name"`
+			Description   *string                   `json:"description"`
+			Price         *int64                    `json:"price"`
+			Active        *bool                     `json:"active"`
+			URL           *string                   `json:"url"`
+			Downloadables *[]downloadableReference  `json:"downloadables"`
+		} `json:"product"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		if r.Body == nil {
+			sendError(w, badRequest("The request body is missing"))
+			return
+		}
+
+		var request request
+		if err := receiveJSON(r.Body, &request); err != nil {
+			sendError(w, badRequest("The request body is not valid JSON"))
+			return
+		}
+
+		product, err := s.productRepository.Get(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		patch := request.Product
+		if patch.Type != nil {
+			product.Type = *patch.Type
+		}
+		if patch.Name != nil {
+			product.Name = *patch.Name
+		}
+		if patch.Description != nil {
+			product.Description = *patch.Description
+		}
+		if patch.Price != nil {
+			product.Price = *patch.Price
+		}
+		if patch.Active != nil {
+			product.Active = *patch.Active
+		}
+		if patch.URL != nil {
+			product.URL = *patch.URL
+		}
+		if patch.Downloadables != nil {
+			downloadables, err := s.productDownloadables(ctx, *patch.Downloadables)
+			if err != nil {
+				sendError(w, err)
+				return
+			}
+			product.Downloadable = downloadables
+		}
+
+		product.Updated = s.timeService.Now()
+
+		if err := product.Validate(); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if err := s.productRepository.Update(ctx, product); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, product)
+	}
+}
+
+// postArchiveProduct takes the product off sale without deleting it,
+// so orders and downloads that refer to it keep working
+func (s *Server) postArchiveProduct() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		product, err := s.productRepository.Get(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if !product.Active {
+			sendJSON(w, http.StatusOK, product)
+			return
+		}
+
+		product.Active = false
+		product.Updated = s.timeService.Now()
+
+		if err := s.productRepository.Update(ctx, product); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, product)
+	}
+}
+
+func (s *Server) deleteProduct() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+		id := params.ByName("id")
+
+		if _, err := s.productRepository.Get(ctx, id); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		referenced, err := s.productReferenceChecker.ProductReferenced(ctx, id)
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+
+		if referenced {
+			sendError(w, conflict("The product is part of existing orders, archive it instead"))
+			return
+		}
+
+		if err := s.productRepository.Delete(ctx, id); err != nil {
+			sendError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// productDownloadables looks up the downloadables referenced by a product request
+func (s *Server) productDownloadables(ctx context.Context, references []downloadableReference) ([]minicommerce.Downloadable, error) {
+	var downloadables []minicommerce.Downloadable
+	for _, d := range references {
+		// this can be optimized by using firestore getAll Document refs
+		downloadable, err := s.downloadableRepository.Get(ctx, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		downloadables = append(downloadables, *downloadable)
+	}
+
+	return downloadables, nil
+}
@@ -26,23 +26,26 @@ func setupProductHTTPServer(t *testing.T) (*Server, *mocks.MockProductRepository
 	*mocks.MockDownloadableRepository,
 	*mocks.MockTimeService,
 	*mocks.MockIDGenerator,
+	*mocks.MockProductReferenceChecker,
 	func()) {
 	ctrl := gomock.NewController(t)
 	repo := mocks.NewMockProductRepository(ctrl)
 	dRepo := mocks.NewMockDownloadableRepository(ctrl)
 	time := mocks.NewMockTimeService(ctrl)
 	uuidGenerator := mocks.NewMockIDGenerator(ctrl)
+	references := mocks.NewMockProductReferenceChecker(ctrl)
 
 	server := Server{
-		productRepository:      repo,
-		downloadableRepository: dRepo,
-		timeService:            time,
-		idGenerator:            uuidGenerator,
-		router:                 httprouter.New(),
+		productRepository:       repo,
+		productReferenceChecker: references,
+		downloadableRepository:  dRepo,
+		timeService:             time,
+		idGenerator:             uuidGenerator,
+		router:                  httprouter.New(),
 	}
 	server.routes()
 
-	return &server, repo, dRepo, time, uuidGenerator, func() {
+	return &server, repo, dRepo, time, uuidGenerator, references, func() {
 		ctrl.Finish()
 	}
 }
@@ -93,10 +96,10 @@ func TestProducts_GetAllProducts(t *testing.T) {
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			server, repo, _, _, _, f := setupProductHTTPServer(t)
+			server, repo, _, _, _, _, f := setupProductHTTPServer(t)
 			defer f()
 
-			repo.EXPECT().GetAll(gomock.Any()).Times(1).Return(tC.products, nil)
+			repo.EXPECT().List(gomock.Any(), minicommerce.ProductFilter{}, minicommerce.ListOptions{}).Times(1).Return(&minicommerce.ProductPage{Products: tC.products}, nil)
 
 			recorder := httptest.NewRecorder()
 			r, err := http.NewRequest(http.MethodGet, "/api/products", nil)
@@ -119,6 +122,77 @@ func TestProducts_GetAllProducts(t *testing.T) {
 	}
 }
 
+func TestProducts_ListProducts(t *testing.T) {
+	active := true
+	testCases := []struct {
+		desc   string
+		url    string
+		filter *minicommerce.ProductFilter
+		opts   minicommerce.ListOptions
+		page   *minicommerce.ProductPage
+	}{
+		{
+			desc:   "The filters and the list options are passed on and the response links to the next page",
+			url:    "/api/products?active=true&type=digital&limit=1&order=desc",
+			filter: &minicommerce.ProductFilter{Active: &active, Type: minicommerce.ProductTypeDigital},
+			opts:   minicommerce.ListOptions{Limit: 1, Order: minicommerce.SortDescending},
+			page: &minicommerce.ProductPage{
+				Products:   []minicommerce.Product{{ID: "product-one", Type: minicommerce.ProductTypeDigital, Active: true}},
+				NextCursor: "next-cursor",
+			},
+		},
+		{
+			desc:   "Continuing from a cursor will pass it on and leave out the next link on the last page",
+			url:    "/api/products?cursor=next-cursor&limit=1",
+			filter: &minicommerce.ProductFilter{},
+			opts:   minicommerce.ListOptions{Limit: 1, Cursor: "next-cursor"},
+			page: &minicommerce.ProductPage{
+				Products: []minicommerce.Product{{ID: "product-two", Type: minicommerce.ProductTypeDigital, Active: true}},
+			},
+		},
+		{
+			desc: "A limit above the maximum will return 400",
+			url:  "/api/products?limit=1000",
+		},
+		{
+			desc: "An unknown sort order will return 400",
+			url:  "/api/products?order=sideways",
+		},
+		{
+			desc: "An unknown product type will return 400",
+			url:  "/api/products?type=physical",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, _, _, f := setupProductHTTPServer(t)
+			defer f()
+
+			if tC.filter != nil {
+				repo.EXPECT().List(gomock.Any(), *tC.filter, tC.opts).Times(1).Return(tC.page, nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, tC.url, nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
 func TestProducts_GetProductByID(t *testing.T) {
 	testCases := []struct {
 		desc    string
@@ -157,7 +231,7 @@ func TestProducts_GetProductByID(t *testing.T) {
 
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			server, repo, _, _, _, finalize := setupProductHTTPServer(t)
+			server, repo, _, _, _, _, finalize := setupProductHTTPServer(t)
 			defer finalize()
 
 			repo.EXPECT().Get(gomock.Any(), tC.id).Times(1).Return(tC.product, tC.err)
@@ -244,7 +318,7 @@ func TestProducts_PostProductResponses(t *testing.T) {
 					Description: "testing a product create description",
 					Price:       20000,
 					Active:      true,
-					URL:         "testing-url-thingie",
+					URL:         "https://example.com/testing-url-thingie",
 				},
 			},
 		},
@@ -252,7 +326,7 @@ func TestProducts_PostProductResponses(t *testing.T) {
 
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			server, repo, mockDownloadables, time, idgenerator, finalize := setupProductHTTPServer(t)
+			server, repo, mockDownloadables, time, idgenerator, _, finalize := setupProductHTTPServer(t)
 			defer finalize()
 
 			for _, d := range tC.request.Product.Downloadables {
@@ -299,7 +373,8 @@ func TestProducts_PostProductErrors(t *testing.T) {
 		ID string `json:"id,omitempty"`
 	}
 	type product struct {
-		Downloadables []downloadable `json:"downloadables,omitempty"`
+		Type          minicommerce.ProductType `json:"type,omitempty"`
+		Downloadables []downloadable           `json:"downloadables,omitempty"`
 	}
 	type request struct {
 		Product product `json:"product,omitempty"`
@@ -322,12 +397,13 @@ func TestProducts_PostProductErrors(t *testing.T) {
 					},
 				},
 			},
-			downloadableErr: errors.New("not found"),
+			downloadableErr: &firestore.DocumentNotFoundError{},
 		},
 		{
 			desc: "When the repository fails, we return an http 500",
 			request: request{
 				Product: product{
+					Type: minicommerce.ProductTypeDigital,
 					Downloadables: []downloadable{
 						{
 							ID: "something-that-does-not-exist",
@@ -340,7 +416,7 @@ func TestProducts_PostProductErrors(t *testing.T) {
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			server, repo, mockDownloadables, time, idgenerator, finalize := setupProductHTTPServer(t)
+			server, repo, mockDownloadables, time, idgenerator, _, finalize := setupProductHTTPServer(t)
 			defer finalize()
 
 			time.EXPECT().Now().Times(1).Return(int64(123321))
@@ -431,6 +507,7 @@ func TestProducts_PostProductRepositoryInputs(t *testing.T) {
 					Description:   "testing repository insertion",
 					Price:         25000,
 					Active:        true,
+					URL:           "https://example.com",
 					Downloadables: nil,
 				},
 			},
@@ -438,7 +515,7 @@ func TestProducts_PostProductRepositoryInputs(t *testing.T) {
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			server, repo, mockDownloadables, time, idgenerator, finalize := setupProductHTTPServer(t)
+			server, repo, mockDownloadables, time, idgenerator, _, finalize := setupProductHTTPServer(t)
 			defer finalize()
 
 			time.EXPECT().Now().Times(1).Return(int64(123321))
@@ -472,3 +549,188 @@ func TestProducts_PostProductRepositoryInputs(t *testing.T) {
 		})
 	}
 }
+
+func TestProducts_PutProduct(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		body    string
+		product *minicommerce.Product
+		err     error
+		updates bool
+	}{
+		{
+			desc:    "Put product will replace the product and bump updated",
+			body:    `{"product":{"type":"digital","name":"New name","description":"New description","price":20000,"active":true,"downloadables":[{"id":"downloadable-one"}]}}`,
+			product: &minicommerce.Product{ID: "product-one", Created: 1, Updated: 1, Type: minicommerce.ProductTypeLink, Name: "Old name", Price: 10000, URL: "https://example.com"},
+			updates: true,
+		},
+		{
+			desc:    "An invalid product will return 422 with the invalid fields",
+			body:    `{"product":{"type":"linkable","name":"New name","price":-100,"url":"not a url"}}`,
+			product: &minicommerce.Product{ID: "product-one", Created: 1, Updated: 1, Type: minicommerce.ProductTypeLink, Name: "Old name", Price: 10000, URL: "https://example.com"},
+		},
+		{
+			desc: "When no product exists, it will return 404",
+			body: `{"product":{"name":"New name"}}`,
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, downloadables, time, _, _, finalize := setupProductHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(tC.product, tC.err)
+
+			if tC.product != nil {
+				time.EXPECT().Now().AnyTimes().Return(int64(2))
+			}
+
+			var updated minicommerce.Product
+			if tC.updates {
+				downloadables.EXPECT().Get(gomock.Any(), "downloadable-one").Times(1).Return(&minicommerce.Downloadable{ID: "downloadable-one", Name: "file.pdf"}, nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, p *minicommerce.Product) {
+					updated = *p
+				}).Times(1).Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodPut, "/api/products/product-one", bytes.NewReader([]byte(tC.body)))
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status  int
+				body    string
+				updated minicommerce.Product
+			}{
+				status:  recorder.Code,
+				body:    recorder.Body.String(),
+				updated: updated,
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
+
+func TestProducts_PatchProduct(t *testing.T) {
+	server, repo, _, time, _, _, finalize := setupProductHTTPServer(t)
+	defer finalize()
+
+	product := &minicommerce.Product{
+		ID:           "product-one",
+		Created:      1,
+		Updated:      1,
+		Type:         minicommerce.ProductTypeDigital,
+		Name:         "Old name",
+		Description:  "Old description",
+		Price:        10000,
+		Active:       true,
+		Downloadable: []minicommerce.Downloadable{{ID: "downloadable-one"}},
+	}
+
+	repo.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(product, nil)
+	time.EXPECT().Now().Times(1).Return(int64(2))
+
+	var updated minicommerce.Product
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, p *minicommerce.Product) {
+		updated = *p
+	}).Times(1).Return(nil)
+
+	recorder := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodPatch, "/api/products/product-one", bytes.NewReader([]byte(`{"product":{"price":12500,"active":false}}`)))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	server.router.ServeHTTP(recorder, r)
+
+	cupaloy.SnapshotT(t, recorder.Code, updated)
+}
+
+func TestProducts_ArchiveProduct(t *testing.T) {
+	server, repo, _, time, _, _, finalize := setupProductHTTPServer(t)
+	defer finalize()
+
+	repo.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one", Updated: 1, Active: true}, nil)
+	time.EXPECT().Now().Times(1).Return(int64(2))
+
+	var updated minicommerce.Product
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, p *minicommerce.Product) {
+		updated = *p
+	}).Times(1).Return(nil)
+
+	recorder := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodPost, "/api/products/product-one/archive", nil)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	server.router.ServeHTTP(recorder, r)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected the product to be archived, got %d", recorder.Code)
+	}
+
+	if updated.Active || updated.Updated != 2 {
+		t.Errorf("expected the product to be inactive and updated, got %+v", updated)
+	}
+}
+
+func TestProducts_DeleteProduct(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		err        error
+		referenced bool
+		deletes    bool
+	}{
+		{
+			desc:    "Deleting a product no order refers to will delete it",
+			deletes: true,
+		},
+		{
+			desc:       "When orders refer to the product, it will return 409",
+			referenced: true,
+		},
+		{
+			desc: "When no product exists, it will return 404",
+			err:  &firestore.DocumentNotFoundError{},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server, repo, _, _, _, references, finalize := setupProductHTTPServer(t)
+			defer finalize()
+
+			repo.EXPECT().Get(gomock.Any(), "product-one").Times(1).Return(&minicommerce.Product{ID: "product-one"}, tC.err)
+			if tC.err == nil {
+				references.EXPECT().ProductReferenced(gomock.Any(), "product-one").Times(1).Return(tC.referenced, nil)
+			}
+			if tC.deletes {
+				repo.EXPECT().Delete(gomock.Any(), "product-one").Times(1).Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodDelete, "/api/products/product-one", nil)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			server.router.ServeHTTP(recorder, r)
+
+			resp := struct {
+				status int
+				body   string
+			}{
+				status: recorder.Code,
+				body:   recorder.Body.String(),
+			}
+
+			cupaloy.SnapshotT(t, resp)
+		})
+	}
+}
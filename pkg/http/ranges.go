@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eikc/minicommerce"
+)
+
+// errRangeNotSatisfiable is returned when the requested range starts after the end of the file
+var errRangeNotSatisfiable = errors.New("the range is not satisfiable")
+
+// byteRange is a single range of bytes in a file
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+// contentRange is the Content-Range header of the range in a file of size
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// storageETag is the ETag of a stored file. It is a strong ETag of the MD5 of the content when the backend has one,
+// otherwise a weak ETag of the modified time and the size
+func storageETag(attrs *minicommerce.StorageAttributes) string {
+	if len(attrs.MD5) > 0 {
+		return fmt.Sprintf(`"%s"`, hex.EncodeToString(attrs.MD5))
+	}
+
+	return fmt.Sprintf(`W/"%x-%x"`, attrs.ModTime, attrs.Size)
+}
+
+// notModified reports whether the copy the client has cached is still current. If-None-Match takes precedence
+// over If-Modified-Since, as RFC 7232 requires
+func notModified(r *http.Request, etag string, modTime int64) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && modTime <= t.Unix()
+	}
+
+	return false
+}
+
+// requestedRange returns the range of the file the client asked for. ok is false when the whole file should be sent,
+// which is the case without a Range header, with a Range header that can't be parsed or asks for more than one range,
+// and when the If-Range validator no longer matches the file
+func requestedRange(r *http.Request, etag string, attrs *minicommerce.StorageAttributes) (rng byteRange, ok bool, err error) {
+	header := r.Header.Get("Range")
+	if header == "" || !rangeStillValid(r.Header.Get("If-Range"), etag, attrs.ModTime) {
+		return rng, false, nil
+	}
+
+	return parseRange(header, attrs.Size)
+}
+
+// rangeStillValid reports whether the If-Range validator matches the file. An ETag must match strongly
+func rangeStillValid(ifRange, etag string, modTime int64) bool {
+	if ifRange == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return ifRange == etag && !strings.HasPrefix(etag, "W/")
+	}
+
+	t, err := http.ParseTime(ifRange)
+	return err == nil && t.Unix() == modTime
+}
+
+// parseRange parses a Range header with a single range of bytes of a file of size, as described in RFC 7233
+func parseRange(header string, size int64) (rng byteRange, ok bool, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) || strings.Contains(header, ",") {
+		return rng, false, nil
+	}
+
+	spec := strings.TrimSpace(header[len(prefix):])
+	dash := strings.Index(spec, "-")
+	if dash < 0 {
+		return rng, false, nil
+	}
+
+	first, last := strings.TrimSpace(spec[:dash]), strings.TrimSpace(spec[dash+1:])
+	if first == "" {
+		// a suffix range asks for the last bytes of the file
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return rng, false, nil
+		}
+		if n == 0 || size == 0 {
+			return rng, false, errRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+
+		return byteRange{start: size - n, length: n}, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return rng, false, nil
+	}
+	if start >= size {
+		return rng, false, errRangeNotSatisfiable
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return rng, false, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return byteRange{start: start, length: end - start + 1}, true, nil
+}
+
+// httpTime formats the unix time for the Last-Modified header
+func httpTime(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(http.TimeFormat)
+}
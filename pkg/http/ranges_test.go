@@ -0,0 +1,35 @@
+package http
+
+import (
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		header string
+		rng    byteRange
+		ok     bool
+		err    error
+	}{
+		{desc: "An open range runs to the end of the file", header: "bytes=10-", rng: byteRange{start: 10, length: 90}, ok: true},
+		{desc: "A closed range is inclusive", header: "bytes=0-9", rng: byteRange{start: 0, length: 10}, ok: true},
+		{desc: "A range ending after the file is cut at the end", header: "bytes=90-200", rng: byteRange{start: 90, length: 10}, ok: true},
+		{desc: "A suffix range is the last bytes of the file", header: "bytes=-5", rng: byteRange{start: 95, length: 5}, ok: true},
+		{desc: "A suffix range longer than the file is the whole file", header: "bytes=-500", rng: byteRange{start: 0, length: 100}, ok: true},
+		{desc: "A range starting at the end of the file is not satisfiable", header: "bytes=100-", err: errRangeNotSatisfiable},
+		{desc: "An empty suffix range is not satisfiable", header: "bytes=-0", err: errRangeNotSatisfiable},
+		{desc: "Multiple ranges are ignored", header: "bytes=0-1,5-6"},
+		{desc: "Another unit is ignored", header: "items=0-1"},
+		{desc: "A range ending before it starts is ignored", header: "bytes=9-1"},
+		{desc: "A range that is not a number is ignored", header: "bytes=a-b"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			rng, ok, err := parseRange(tC.header, 100)
+			if rng != tC.rng || ok != tC.ok || err != tC.err {
+				t.Errorf("parseRange(%q) = %v, %v, %v, want %v, %v, %v", tC.header, rng, ok, err, tC.rng, tC.ok, tC.err)
+			}
+		})
+	}
+}
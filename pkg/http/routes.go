@@ -8,9 +8,38 @@ func (s *Server) routes() {
 	// Downloadables
 	s.router.Handle(http.MethodGet, "/api/downloadables", s.getAllDownloadables())
 	s.router.Handle(http.MethodPost, "/api/downloadables", s.postDownloadables())
+	s.router.Handle(http.MethodDelete, "/api/downloadables/:id", s.deleteDownloadable())
 
 	// Products
 	s.router.Handle(http.MethodGet, "/api/products", s.getAllProducts())
 	s.router.Handle(http.MethodGet, "/api/products/:id", s.getProductByID())
 	s.router.Handle(http.MethodPost, "/api/products", s.postProduct())
+	s.router.Handle(http.MethodPut, "/api/products/:id", s.putProduct())
+	s.router.Handle(http.MethodPatch, "/api/products/:id", s.patchProduct())
+	s.router.Handle(http.MethodPost, "/api/products/:id/archive", s.postArchiveProduct())
+	s.router.Handle(http.MethodDelete, "/api/products/:id", s.deleteProduct())
+
+	// Orders
+	s.router.Handle(http.MethodGet, "/api/orders", s.getAllOrders())
+	s.router.Handle(http.MethodGet, "/api/orders/:id", s.getOrderByID())
+	s.router.Handle(http.MethodPost, "/api/orders", s.postOrder())
+	s.router.Handle(http.MethodPut, "/api/orders/:id", s.putOrder())
+	s.router.Handle(http.MethodPost, "/api/orders/:id/checkout", s.postCheckout())
+	s.router.Handle(http.MethodGet, "/api/orders/:id/downloads", s.getOrderDownloads())
+
+	// Downloads
+	s.router.Handle(http.MethodGet, "/api/downloads/:orderId/:downloadableId", s.getDownload())
+	s.router.Handle(http.MethodHead, "/api/downloads/:orderId/:downloadableId", s.getDownload())
+
+	// Coupons
+	s.router.Handle(http.MethodGet, "/api/coupons", s.getAllCoupons())
+	s.router.Handle(http.MethodGet, "/api/coupons/:code", s.getCouponByCode())
+	s.router.Handle(http.MethodPost, "/api/coupons", s.postCoupon())
+	s.router.Handle(http.MethodPut, "/api/coupons/:code", s.putCoupon())
+	s.router.Handle(http.MethodPost, "/api/coupons/:code/deactivate", s.postDeactivateCoupon())
+	s.router.Handle(http.MethodPost, "/api/coupon-batches", s.postCouponBatch())
+	s.router.Handle(http.MethodGet, "/api/coupon-batches/:id", s.getCouponBatch())
+
+	// Payments
+	s.router.Handle(http.MethodPost, "/api/payments/webhook", s.postPaymentWebhook())
 }
@@ -1,40 +1,116 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/pricing"
 	"github.com/julienschmidt/httprouter"
 )
 
+// shutdownTimeout is how long the requests in progress get to finish when the server is stopped
+const shutdownTimeout = 30 * time.Second
+
 // Server is the http server for serving the minicommerce rest API
 type Server struct {
-	downloadableRepository minicommerce.DownloadableRepository
-	productRepository      minicommerce.ProductRepository
-	storage                minicommerce.Storage
-	idGenerator            minicommerce.IDGenerator
-	timeService            minicommerce.TimeService
-	router                 *httprouter.Router
+	downloadableRepository       minicommerce.DownloadableRepository
+	downloadableReferenceChecker minicommerce.DownloadableReferenceChecker
+	productRepository            minicommerce.ProductRepository
+	productReferenceChecker      minicommerce.ProductReferenceChecker
+	orderRepository              minicommerce.OrderRepository
+	orderEditor                  minicommerce.OrderEditor
+	checkoutService              minicommerce.CheckoutService
+	paymentRepository            minicommerce.PaymentRepository
+	paymentEventRepository       minicommerce.PaymentEventRepository
+	orderRefunder                minicommerce.OrderRefunder
+	couponRepository             minicommerce.CouponRepository
+	couponGenerator              minicommerce.CouponGenerator
+	couponValidator              minicommerce.CouponValidator
+	downloadService              minicommerce.DownloadService
+	downloadSigner               minicommerce.DownloadSigner
+	storage                      minicommerce.Storage
+	idGenerator                  minicommerce.IDGenerator
+	timeService                  minicommerce.TimeService
+	webhookSecret                WebhookSecret
+	trustedProxies               TrustedProxies
+	rules                        pricing.Rules
+	router                       *httprouter.Router
 }
 
 // NewServer is the constructor for the Http Server
 func NewServer(downloadableRepository minicommerce.DownloadableRepository,
+	downloadableReferenceChecker minicommerce.DownloadableReferenceChecker,
+	productRepository minicommerce.ProductRepository,
+	productReferenceChecker minicommerce.ProductReferenceChecker,
+	orderRepository minicommerce.OrderRepository,
+	orderEditor minicommerce.OrderEditor,
+	checkoutService minicommerce.CheckoutService,
+	paymentRepository minicommerce.PaymentRepository,
+	paymentEventRepository minicommerce.PaymentEventRepository,
+	orderRefunder minicommerce.OrderRefunder,
+	couponRepository minicommerce.CouponRepository,
+	couponGenerator minicommerce.CouponGenerator,
+	couponValidator minicommerce.CouponValidator,
+	downloadService minicommerce.DownloadService,
+	downloadSigner minicommerce.DownloadSigner,
 	storage minicommerce.Storage,
 	timeService minicommerce.TimeService,
-	idGenerator minicommerce.IDGenerator) *Server {
+	idGenerator minicommerce.IDGenerator,
+	webhookSecret WebhookSecret,
+	trustedProxies TrustedProxies,
+	rules pricing.Rules) *Server {
 
 	return &Server{
-		downloadableRepository: downloadableRepository,
-		idGenerator:            idGenerator,
-		timeService:            timeService,
-		storage:                storage,
-		router:                 httprouter.New(),
+		downloadableRepository:       downloadableRepository,
+		downloadableReferenceChecker: downloadableReferenceChecker,
+		productRepository:            productRepository,
+		productReferenceChecker:      productReferenceChecker,
+		orderRepository:              orderRepository,
+		orderEditor:                  orderEditor,
+		checkoutService:              checkoutService,
+		paymentRepository:            paymentRepository,
+		paymentEventRepository:       paymentEventRepository,
+		orderRefunder:                orderRefunder,
+		couponRepository:             couponRepository,
+		couponGenerator:              couponGenerator,
+		couponValidator:              couponValidator,
+		downloadService:              downloadService,
+		downloadSigner:               downloadSigner,
+		idGenerator:                  idGenerator,
+		timeService:                  timeService,
+		storage:                      storage,
+		webhookSecret:                webhookSecret,
+		trustedProxies:               trustedProxies,
+		rules:                        rules,
+		router:                       httprouter.New(),
 	}
 }
 
-// Run starts the server with all the given params
-func (s *Server) Run(port string) error {
+// Run starts the server with all the given params. When the context is done the server stops accepting requests
+// and waits for the ones in progress, so the dependencies can be closed safely once Run returns
+func (s *Server) Run(ctx context.Context, port string) error {
 	s.routes()
-	return http.ListenAndServe(fmt.Sprintf(":%s", port), s.router)
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", port),
+		Handler: s.router,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// sniffLen is the number of bytes http.DetectContentType looks at
+const sniffLen = 512
+
+// digest hashes and counts the content of an upload while it is streamed to the storage, so the file only has
+// to be read once. The start of it is sniffed up front, so its content type can be stored along with it
+type digest struct {
+	r     io.Reader
+	hash  hash.Hash
+	size  int64
+	sniff []byte
+}
+
+func newDigest(r io.Reader) *digest {
+	buffered := bufio.NewReaderSize(r, sniffLen)
+
+	// Peek returns what there is when the upload is shorter, the error is left to the first read
+	sniff, _ := buffered.Peek(sniffLen)
+
+	return &digest{r: buffered, hash: sha256.New(), sniff: append([]byte(nil), sniff...)}
+}
+
+func (d *digest) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.hash.Write(p[:n])
+	d.size += int64(n)
+
+	return n, err
+}
+
+// sum is the hex encoded SHA-256 of everything that has been read
+func (d *digest) sum() string {
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
+
+// contentType is the content type detected from the first bytes of the file. When the content can't be
+// recognized the extension of the filename is used instead
+func (d *digest) contentType(filename string) string {
+	contentType := http.DetectContentType(d.sniff)
+	if contentType == "application/octet-stream" {
+		if byExtension := mime.TypeByExtension(path.Ext(filename)); byExtension != "" {
+			return byExtension
+		}
+	}
+
+	return contentType
+}
@@ -0,0 +1,85 @@
+package migrate
+
+import (
+	"context"
+	"sort"
+
+	"github.com/eikc/minicommerce"
+)
+
+// Locations moves the files of downloadables that were uploaded before they got a collision free storage key
+type Locations struct {
+	downloadableReader  minicommerce.DownloadableReader
+	downloadableUpdater minicommerce.DownloadableUpdater
+	storageCopier       minicommerce.StorageCopier
+}
+
+// LocationsReport is the outcome of a migration. Unchanged counts the downloadables that weren't at the root of
+// the storage. Shared lists the old locations that more than one downloadable pointed at, those files were
+// overwritten by the last upload, so all of those downloadables get that file
+type LocationsReport struct {
+	Migrated  int
+	Unchanged int
+	Shared    []string
+}
+
+// NewLocations is the constructor for the Locations migration
+func NewLocations(downloadableReader minicommerce.DownloadableReader,
+	downloadableUpdater minicommerce.DownloadableUpdater,
+	storageCopier minicommerce.StorageCopier) *Locations {
+
+	return &Locations{
+		downloadableReader:  downloadableReader,
+		downloadableUpdater: downloadableUpdater,
+		storageCopier:       storageCopier,
+	}
+}
+
+// Run copies the file of every downloadable at a legacy location to its DownloadableLocation and points the
+// downloadable at the copy. Downloadables in the downloadables folder are left alone, even when they share the file
+// of another downloadable. The old files are left in place, so a migration that stops half way can safely be run again
+func (l *Locations) Run(ctx context.Context) (*LocationsReport, error) {
+	downloadables, err := l.downloadableReader.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LocationsReport{}
+	users := make(map[string]int)
+	for _, downloadable := range downloadables {
+		if minicommerce.LegacyLocation(downloadable.Location) {
+			users[downloadable.Location]++
+		}
+	}
+
+	for location, n := range users {
+		if n > 1 {
+			report.Shared = append(report.Shared, location)
+		}
+	}
+	sort.Strings(report.Shared)
+
+	for i := range downloadables {
+		downloadable := &downloadables[i]
+
+		if !minicommerce.LegacyLocation(downloadable.Location) {
+			report.Unchanged++
+			continue
+		}
+
+		location := minicommerce.DownloadableLocation(downloadable.ID, downloadable.Name)
+
+		if err := l.storageCopier.Copy(ctx, downloadable.Location, location); err != nil {
+			return report, err
+		}
+
+		downloadable.Location = location
+		if err := l.downloadableUpdater.Update(ctx, downloadable); err != nil {
+			return report, err
+		}
+
+		report.Migrated++
+	}
+
+	return report, nil
+}
@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eikc/minicommerce"
+	"github.com/eikc/minicommerce/pkg/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func setupLocations(t *testing.T) (*Locations, *mocks.MockDownloadableRepository, *mocks.MockStorage, func()) {
+	ctrl := gomock.NewController(t)
+	downloadables := mocks.NewMockDownloadableRepository(ctrl)
+	storage := mocks.NewMockStorage(ctrl)
+
+	return NewLocations(downloadables, downloadables, storage), downloadables, storage, func() {
+		ctrl.Finish()
+	}
+}
+
+func TestLocationsRun(t *testing.T) {
+	migration, downloadables, storage, finalize := setupLocations(t)
+	defer finalize()
+
+	downloadables.EXPECT().GetAll(gomock.Any()).Times(1).Return([]minicommerce.Downloadable{
+		{ID: "one", Name: "ebook.pdf", Location: "ebook.pdf"},
+		{ID: "two", Name: "ebook.pdf", Location: "ebook.pdf"},
+		{ID: "three", Name: "video.mp4", Location: "downloadables/three/video.mp4"},
+		{ID: "four", Name: "clip.mp4", Location: "downloadables/three/video.mp4"},
+	}, nil)
+
+	for _, id := range []string{"one", "two"} {
+		location := "downloadables/" + id + "/ebook.pdf"
+		storage.EXPECT().Copy(gomock.Any(), "ebook.pdf", location).Times(1).Return(nil)
+		downloadables.EXPECT().Update(gomock.Any(), &minicommerce.Downloadable{ID: id, Name: "ebook.pdf", Location: location}).Times(1).Return(nil)
+	}
+
+	report, err := migration.Run(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := &LocationsReport{Migrated: 2, Unchanged: 2, Shared: []string{"ebook.pdf"}}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected the report to be %v, got %v", expected, report)
+	}
+}
+
+func TestLocationsRunStopsWhenTheCopyFails(t *testing.T) {
+	migration, downloadables, storage, finalize := setupLocations(t)
+	defer finalize()
+
+	downloadables.EXPECT().GetAll(gomock.Any()).Times(1).Return([]minicommerce.Downloadable{
+		{ID: "one", Name: "ebook.pdf", Location: "ebook.pdf"},
+		{ID: "two", Name: "video.mp4", Location: "video.mp4"},
+	}, nil)
+
+	storageErr := errors.New("object not found")
+	storage.EXPECT().Copy(gomock.Any(), "ebook.pdf", "downloadables/one/ebook.pdf").Times(1).Return(storageErr)
+
+	report, err := migration.Run(context.Background())
+	if err != storageErr {
+		t.Errorf("expected the storage error, got %v", err)
+	}
+
+	if report.Migrated != 0 {
+		t.Errorf("expected no downloadable to be migrated, got %d", report.Migrated)
+	}
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: checkout.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	minicommerce "github.com/eikc/minicommerce"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockCheckoutService is a mock of CheckoutService interface
+type MockCheckoutService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckoutServiceMockRecorder
+}
+
+// MockCheckoutServiceMockRecorder is the mock recorder for MockCheckoutService
+type MockCheckoutServiceMockRecorder struct {
+	mock *MockCheckoutService
+}
+
+// NewMockCheckoutService creates a new mock instance
+func NewMockCheckoutService(ctrl *gomock.Controller) *MockCheckoutService {
+	mock := &MockCheckoutService{ctrl: ctrl}
+	mock.recorder = &MockCheckoutServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCheckoutService) EXPECT() *MockCheckoutServiceMockRecorder {
+	return m.recorder
+}
+
+// Checkout mocks base method
+func (m *MockCheckoutService) Checkout(ctx context.Context, orderID string) (*minicommerce.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", ctx, orderID)
+	ret0, _ := ret[0].(*minicommerce.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkout indicates an expected call of Checkout
+func (mr *MockCheckoutServiceMockRecorder) Checkout(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockCheckoutService)(nil).Checkout), ctx, orderID)
+}
+
+// MockOrderPlacer is a mock of OrderPlacer interface
+type MockOrderPlacer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderPlacerMockRecorder
+}
+
+// MockOrderPlacerMockRecorder is the mock recorder for MockOrderPlacer
+type MockOrderPlacerMockRecorder struct {
+	mock *MockOrderPlacer
+}
+
+// NewMockOrderPlacer creates a new mock instance
+func NewMockOrderPlacer(ctrl *gomock.Controller) *MockOrderPlacer {
+	mock := &MockOrderPlacer{ctrl: ctrl}
+	mock.recorder = &MockOrderPlacerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrderPlacer) EXPECT() *MockOrderPlacerMockRecorder {
+	return m.recorder
+}
+
+// Place mocks base method
+func (m *MockOrderPlacer) Place(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Place", ctx, order, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Place indicates an expected call of Place
+func (mr *MockOrderPlacerMockRecorder) Place(ctx, order, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Place", reflect.TypeOf((*MockOrderPlacer)(nil).Place), ctx, order, payment)
+}
+
+// Cancel mocks base method
+func (m *MockOrderPlacer) Cancel(ctx context.Context, order *minicommerce.Order, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, order, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel
+func (mr *MockOrderPlacerMockRecorder) Cancel(ctx, order, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockOrderPlacer)(nil).Cancel), ctx, order, payment)
+}
+
+// MockOrderEditor is a mock of OrderEditor interface
+type MockOrderEditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEditorMockRecorder
+}
+
+// MockOrderEditorMockRecorder is the mock recorder for MockOrderEditor
+type MockOrderEditorMockRecorder struct {
+	mock *MockOrderEditor
+}
+
+// NewMockOrderEditor creates a new mock instance
+func NewMockOrderEditor(ctrl *gomock.Controller) *MockOrderEditor {
+	mock := &MockOrderEditor{ctrl: ctrl}
+	mock.recorder = &MockOrderEditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrderEditor) EXPECT() *MockOrderEditorMockRecorder {
+	return m.recorder
+}
+
+// Edit mocks base method
+func (m *MockOrderEditor) Edit(ctx context.Context, order *minicommerce.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Edit", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Edit indicates an expected call of Edit
+func (mr *MockOrderEditorMockRecorder) Edit(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*MockOrderEditor)(nil).Edit), ctx, order)
+}
@@ -0,0 +1,414 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: coupon.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	minicommerce "github.com/eikc/minicommerce"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockCouponReader is a mock of CouponReader interface
+type MockCouponReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponReaderMockRecorder
+}
+
+// MockCouponReaderMockRecorder is the mock recorder for MockCouponReader
+type MockCouponReaderMockRecorder struct {
+	mock *MockCouponReader
+}
+
+// NewMockCouponReader creates a new mock instance
+func NewMockCouponReader(ctrl *gomock.Controller) *MockCouponReader {
+	mock := &MockCouponReader{ctrl: ctrl}
+	mock.recorder = &MockCouponReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponReader) EXPECT() *MockCouponReaderMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method
+func (m *MockCouponReader) GetAll(ctx context.Context) ([]minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockCouponReaderMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCouponReader)(nil).GetAll), ctx)
+}
+
+// GetByCode mocks base method
+func (m *MockCouponReader) GetByCode(ctx context.Context, code string) (*minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCode", ctx, code)
+	ret0, _ := ret[0].(*minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCode indicates an expected call of GetByCode
+func (mr *MockCouponReaderMockRecorder) GetByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCode", reflect.TypeOf((*MockCouponReader)(nil).GetByCode), ctx, code)
+}
+
+// GetByBatch mocks base method
+func (m *MockCouponReader) GetByBatch(ctx context.Context, batch string) ([]minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByBatch", ctx, batch)
+	ret0, _ := ret[0].([]minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByBatch indicates an expected call of GetByBatch
+func (mr *MockCouponReaderMockRecorder) GetByBatch(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByBatch", reflect.TypeOf((*MockCouponReader)(nil).GetByBatch), ctx, batch)
+}
+
+// MockCouponWriter is a mock of CouponWriter interface
+type MockCouponWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponWriterMockRecorder
+}
+
+// MockCouponWriterMockRecorder is the mock recorder for MockCouponWriter
+type MockCouponWriterMockRecorder struct {
+	mock *MockCouponWriter
+}
+
+// NewMockCouponWriter creates a new mock instance
+func NewMockCouponWriter(ctrl *gomock.Controller) *MockCouponWriter {
+	mock := &MockCouponWriter{ctrl: ctrl}
+	mock.recorder = &MockCouponWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponWriter) EXPECT() *MockCouponWriterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockCouponWriter) Create(ctx context.Context, coupon minicommerce.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockCouponWriterMockRecorder) Create(ctx, coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCouponWriter)(nil).Create), ctx, coupon)
+}
+
+// MockCouponUpdater is a mock of CouponUpdater interface
+type MockCouponUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponUpdaterMockRecorder
+}
+
+// MockCouponUpdaterMockRecorder is the mock recorder for MockCouponUpdater
+type MockCouponUpdaterMockRecorder struct {
+	mock *MockCouponUpdater
+}
+
+// NewMockCouponUpdater creates a new mock instance
+func NewMockCouponUpdater(ctrl *gomock.Controller) *MockCouponUpdater {
+	mock := &MockCouponUpdater{ctrl: ctrl}
+	mock.recorder = &MockCouponUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponUpdater) EXPECT() *MockCouponUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method
+func (m *MockCouponUpdater) Update(ctx context.Context, coupon minicommerce.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockCouponUpdaterMockRecorder) Update(ctx, coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCouponUpdater)(nil).Update), ctx, coupon)
+}
+
+// MockCouponRepository is a mock of CouponRepository interface
+type MockCouponRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponRepositoryMockRecorder
+}
+
+// MockCouponRepositoryMockRecorder is the mock recorder for MockCouponRepository
+type MockCouponRepositoryMockRecorder struct {
+	mock *MockCouponRepository
+}
+
+// NewMockCouponRepository creates a new mock instance
+func NewMockCouponRepository(ctrl *gomock.Controller) *MockCouponRepository {
+	mock := &MockCouponRepository{ctrl: ctrl}
+	mock.recorder = &MockCouponRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponRepository) EXPECT() *MockCouponRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method
+func (m *MockCouponRepository) GetAll(ctx context.Context) ([]minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockCouponRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCouponRepository)(nil).GetAll), ctx)
+}
+
+// GetByCode mocks base method
+func (m *MockCouponRepository) GetByCode(ctx context.Context, code string) (*minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCode", ctx, code)
+	ret0, _ := ret[0].(*minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCode indicates an expected call of GetByCode
+func (mr *MockCouponRepositoryMockRecorder) GetByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCode", reflect.TypeOf((*MockCouponRepository)(nil).GetByCode), ctx, code)
+}
+
+// GetByBatch mocks base method
+func (m *MockCouponRepository) GetByBatch(ctx context.Context, batch string) ([]minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByBatch", ctx, batch)
+	ret0, _ := ret[0].([]minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByBatch indicates an expected call of GetByBatch
+func (mr *MockCouponRepositoryMockRecorder) GetByBatch(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByBatch", reflect.TypeOf((*MockCouponRepository)(nil).GetByBatch), ctx, batch)
+}
+
+// Create mocks base method
+func (m *MockCouponRepository) Create(ctx context.Context, coupon minicommerce.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockCouponRepositoryMockRecorder) Create(ctx, coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCouponRepository)(nil).Create), ctx, coupon)
+}
+
+// Update mocks base method
+func (m *MockCouponRepository) Update(ctx context.Context, coupon minicommerce.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockCouponRepositoryMockRecorder) Update(ctx, coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCouponRepository)(nil).Update), ctx, coupon)
+}
+
+// MockCouponBatchWriter is a mock of CouponBatchWriter interface
+type MockCouponBatchWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponBatchWriterMockRecorder
+}
+
+// MockCouponBatchWriterMockRecorder is the mock recorder for MockCouponBatchWriter
+type MockCouponBatchWriterMockRecorder struct {
+	mock *MockCouponBatchWriter
+}
+
+// NewMockCouponBatchWriter creates a new mock instance
+func NewMockCouponBatchWriter(ctrl *gomock.Controller) *MockCouponBatchWriter {
+	mock := &MockCouponBatchWriter{ctrl: ctrl}
+	mock.recorder = &MockCouponBatchWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponBatchWriter) EXPECT() *MockCouponBatchWriterMockRecorder {
+	return m.recorder
+}
+
+// CreateAll mocks base method
+func (m *MockCouponBatchWriter) CreateAll(ctx context.Context, coupons []minicommerce.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAll", ctx, coupons)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAll indicates an expected call of CreateAll
+func (mr *MockCouponBatchWriterMockRecorder) CreateAll(ctx, coupons interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAll", reflect.TypeOf((*MockCouponBatchWriter)(nil).CreateAll), ctx, coupons)
+}
+
+// MockCouponGenerator is a mock of CouponGenerator interface
+type MockCouponGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponGeneratorMockRecorder
+}
+
+// MockCouponGeneratorMockRecorder is the mock recorder for MockCouponGenerator
+type MockCouponGeneratorMockRecorder struct {
+	mock *MockCouponGenerator
+}
+
+// NewMockCouponGenerator creates a new mock instance
+func NewMockCouponGenerator(ctrl *gomock.Controller) *MockCouponGenerator {
+	mock := &MockCouponGenerator{ctrl: ctrl}
+	mock.recorder = &MockCouponGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponGenerator) EXPECT() *MockCouponGeneratorMockRecorder {
+	return m.recorder
+}
+
+// Generate mocks base method
+func (m *MockCouponGenerator) Generate(ctx context.Context, template minicommerce.Coupon, count int) ([]minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate", ctx, template, count)
+	ret0, _ := ret[0].([]minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate
+func (mr *MockCouponGeneratorMockRecorder) Generate(ctx, template, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockCouponGenerator)(nil).Generate), ctx, template, count)
+}
+
+// MockCouponValidator is a mock of CouponValidator interface
+type MockCouponValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponValidatorMockRecorder
+}
+
+// MockCouponValidatorMockRecorder is the mock recorder for MockCouponValidator
+type MockCouponValidatorMockRecorder struct {
+	mock *MockCouponValidator
+}
+
+// NewMockCouponValidator creates a new mock instance
+func NewMockCouponValidator(ctrl *gomock.Controller) *MockCouponValidator {
+	mock := &MockCouponValidator{ctrl: ctrl}
+	mock.recorder = &MockCouponValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponValidator) EXPECT() *MockCouponValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method
+func (m *MockCouponValidator) Validate(ctx context.Context, code string, order *minicommerce.Order) (*minicommerce.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, code, order)
+	ret0, _ := ret[0].(*minicommerce.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockCouponValidatorMockRecorder) Validate(ctx, code, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockCouponValidator)(nil).Validate), ctx, code, order)
+}
+
+// MockCouponRedeemer is a mock of CouponRedeemer interface
+type MockCouponRedeemer struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponRedeemerMockRecorder
+}
+
+// MockCouponRedeemerMockRecorder is the mock recorder for MockCouponRedeemer
+type MockCouponRedeemerMockRecorder struct {
+	mock *MockCouponRedeemer
+}
+
+// NewMockCouponRedeemer creates a new mock instance
+func NewMockCouponRedeemer(ctrl *gomock.Controller) *MockCouponRedeemer {
+	mock := &MockCouponRedeemer{ctrl: ctrl}
+	mock.recorder = &MockCouponRedeemerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCouponRedeemer) EXPECT() *MockCouponRedeemerMockRecorder {
+	return m.recorder
+}
+
+// Redeem mocks base method
+func (m *MockCouponRedeemer) Redeem(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeem", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeem indicates an expected call of Redeem
+func (mr *MockCouponRedeemerMockRecorder) Redeem(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeem", reflect.TypeOf((*MockCouponRedeemer)(nil).Redeem), ctx, code)
+}
+
+// Release mocks base method
+func (m *MockCouponRedeemer) Release(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release
+func (mr *MockCouponRedeemerMockRecorder) Release(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockCouponRedeemer)(nil).Release), ctx, code)
+}
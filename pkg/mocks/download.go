@@ -0,0 +1,236 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: download.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	minicommerce "github.com/eikc/minicommerce"
+	gomock "github.com/golang/mock/gomock"
+	io "io"
+	reflect "reflect"
+)
+
+// MockDownloadService is a mock of DownloadService interface
+type MockDownloadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadServiceMockRecorder
+}
+
+// MockDownloadServiceMockRecorder is the mock recorder for MockDownloadService
+type MockDownloadServiceMockRecorder struct {
+	mock *MockDownloadService
+}
+
+// NewMockDownloadService creates a new mock instance
+func NewMockDownloadService(ctrl *gomock.Controller) *MockDownloadService {
+	mock := &MockDownloadService{ctrl: ctrl}
+	mock.recorder = &MockDownloadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDownloadService) EXPECT() *MockDownloadServiceMockRecorder {
+	return m.recorder
+}
+
+// Downloadables mocks base method
+func (m *MockDownloadService) Downloadables(ctx context.Context, orderID string) ([]minicommerce.Downloadable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Downloadables", ctx, orderID)
+	ret0, _ := ret[0].([]minicommerce.Downloadable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Downloadables indicates an expected call of Downloadables
+func (mr *MockDownloadServiceMockRecorder) Downloadables(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Downloadables", reflect.TypeOf((*MockDownloadService)(nil).Downloadables), ctx, orderID)
+}
+
+// Stat mocks base method
+func (m *MockDownloadService) Stat(ctx context.Context, orderID, downloadableID string) (*minicommerce.Downloadable, *minicommerce.StorageAttributes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", ctx, orderID, downloadableID)
+	ret0, _ := ret[0].(*minicommerce.Downloadable)
+	ret1, _ := ret[1].(*minicommerce.StorageAttributes)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Stat indicates an expected call of Stat
+func (mr *MockDownloadServiceMockRecorder) Stat(ctx, orderID, downloadableID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockDownloadService)(nil).Stat), ctx, orderID, downloadableID)
+}
+
+// Open mocks base method
+func (m *MockDownloadService) Open(ctx context.Context, orderID, downloadableID string, link minicommerce.DownloadLink, offset int64, client minicommerce.DownloadClient) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, orderID, downloadableID, link, offset, client)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open
+func (mr *MockDownloadServiceMockRecorder) Open(ctx, orderID, downloadableID, link, offset, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockDownloadService)(nil).Open), ctx, orderID, downloadableID, link, offset, client)
+}
+
+// Purchased mocks base method
+func (m *MockDownloadService) Purchased(ctx context.Context, downloadableID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purchased", ctx, downloadableID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purchased indicates an expected call of Purchased
+func (mr *MockDownloadServiceMockRecorder) Purchased(ctx, downloadableID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purchased", reflect.TypeOf((*MockDownloadService)(nil).Purchased), ctx, downloadableID)
+}
+
+// Refuse mocks base method
+func (m *MockDownloadService) Refuse(ctx context.Context, orderID, downloadableID, reason string, client minicommerce.DownloadClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refuse", ctx, orderID, downloadableID, reason, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refuse indicates an expected call of Refuse
+func (mr *MockDownloadServiceMockRecorder) Refuse(ctx, orderID, downloadableID, reason, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refuse", reflect.TypeOf((*MockDownloadService)(nil).Refuse), ctx, orderID, downloadableID, reason, client)
+}
+
+// MockDownloadSigner is a mock of DownloadSigner interface
+type MockDownloadSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadSignerMockRecorder
+}
+
+// MockDownloadSignerMockRecorder is the mock recorder for MockDownloadSigner
+type MockDownloadSignerMockRecorder struct {
+	mock *MockDownloadSigner
+}
+
+// NewMockDownloadSigner creates a new mock instance
+func NewMockDownloadSigner(ctrl *gomock.Controller) *MockDownloadSigner {
+	mock := &MockDownloadSigner{ctrl: ctrl}
+	mock.recorder = &MockDownloadSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDownloadSigner) EXPECT() *MockDownloadSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method
+func (m *MockDownloadSigner) Sign(orderID, downloadableID string, expires int64) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", orderID, downloadableID, expires)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Sign indicates an expected call of Sign
+func (mr *MockDownloadSignerMockRecorder) Sign(orderID, downloadableID, expires interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockDownloadSigner)(nil).Sign), orderID, downloadableID, expires)
+}
+
+// Verify mocks base method
+func (m *MockDownloadSigner) Verify(orderID, downloadableID string, expires int64, signature string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", orderID, downloadableID, expires, signature)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify
+func (mr *MockDownloadSignerMockRecorder) Verify(orderID, downloadableID, expires, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockDownloadSigner)(nil).Verify), orderID, downloadableID, expires, signature)
+}
+
+// MockDownloadCounter is a mock of DownloadCounter interface
+type MockDownloadCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadCounterMockRecorder
+}
+
+// MockDownloadCounterMockRecorder is the mock recorder for MockDownloadCounter
+type MockDownloadCounterMockRecorder struct {
+	mock *MockDownloadCounter
+}
+
+// NewMockDownloadCounter creates a new mock instance
+func NewMockDownloadCounter(ctrl *gomock.Controller) *MockDownloadCounter {
+	mock := &MockDownloadCounter{ctrl: ctrl}
+	mock.recorder = &MockDownloadCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDownloadCounter) EXPECT() *MockDownloadCounterMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method
+func (m *MockDownloadCounter) Count(ctx context.Context, link minicommerce.DownloadLink, access *minicommerce.DownloadAccess, limits minicommerce.DownloadLimits) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, link, access, limits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Count indicates an expected call of Count
+func (mr *MockDownloadCounterMockRecorder) Count(ctx, link, access, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockDownloadCounter)(nil).Count), ctx, link, access, limits)
+}
+
+// MockDownloadAccessLogger is a mock of DownloadAccessLogger interface
+type MockDownloadAccessLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadAccessLoggerMockRecorder
+}
+
+// MockDownloadAccessLoggerMockRecorder is the mock recorder for MockDownloadAccessLogger
+type MockDownloadAccessLoggerMockRecorder struct {
+	mock *MockDownloadAccessLogger
+}
+
+// NewMockDownloadAccessLogger creates a new mock instance
+func NewMockDownloadAccessLogger(ctrl *gomock.Controller) *MockDownloadAccessLogger {
+	mock := &MockDownloadAccessLogger{ctrl: ctrl}
+	mock.recorder = &MockDownloadAccessLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDownloadAccessLogger) EXPECT() *MockDownloadAccessLoggerMockRecorder {
+	return m.recorder
+}
+
+// Log mocks base method
+func (m *MockDownloadAccessLogger) Log(ctx context.Context, access *minicommerce.DownloadAccess) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Log", ctx, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Log indicates an expected call of Log
+func (mr *MockDownloadAccessLoggerMockRecorder) Log(ctx, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockDownloadAccessLogger)(nil).Log), ctx, access)
+}
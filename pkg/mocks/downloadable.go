@@ -49,6 +49,21 @@ func (mr *MockDownloadableReaderMockRecorder) Get(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDownloadableReader)(nil).Get), ctx, id)
 }
 
+// GetByHash mocks base method
+func (m *MockDownloadableReader) GetByHash(ctx context.Context, hash string) (*minicommerce.Downloadable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(*minicommerce.Downloadable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash
+func (mr *MockDownloadableReaderMockRecorder) GetByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockDownloadableReader)(nil).GetByHash), ctx, hash)
+}
+
 // GetAll mocks base method
 func (m *MockDownloadableReader) GetAll(ctx context.Context) ([]minicommerce.Downloadable, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +79,36 @@ func (mr *MockDownloadableReaderMockRecorder) GetAll(ctx interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockDownloadableReader)(nil).GetAll), ctx)
 }
 
+// List mocks base method
+func (m *MockDownloadableReader) List(ctx context.Context, opts minicommerce.ListOptions) (*minicommerce.DownloadablePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, opts)
+	ret0, _ := ret[0].(*minicommerce.DownloadablePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockDownloadableReaderMockRecorder) List(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDownloadableReader)(nil).List), ctx, opts)
+}
+
+// LocationReferenced mocks base method
+func (m *MockDownloadableReader) LocationReferenced(ctx context.Context, location string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocationReferenced", ctx, location)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LocationReferenced indicates an expected call of LocationReferenced
+func (mr *MockDownloadableReaderMockRecorder) LocationReferenced(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocationReferenced", reflect.TypeOf((*MockDownloadableReader)(nil).LocationReferenced), ctx, location)
+}
+
 // MockDownloadableWriter is a mock of DownloadableWriter interface
 type MockDownloadableWriter struct {
 	ctrl     *gomock.Controller
@@ -101,6 +146,57 @@ func (mr *MockDownloadableWriterMockRecorder) Create(ctx, downloadable interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDownloadableWriter)(nil).Create), ctx, downloadable)
 }
 
+// CreateSharingFile mocks base method
+func (m *MockDownloadableWriter) CreateSharingFile(ctx context.Context, downloadable *minicommerce.Downloadable) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSharingFile", ctx, downloadable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSharingFile indicates an expected call of CreateSharingFile
+func (mr *MockDownloadableWriterMockRecorder) CreateSharingFile(ctx, downloadable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSharingFile", reflect.TypeOf((*MockDownloadableWriter)(nil).CreateSharingFile), ctx, downloadable)
+}
+
+// MockDownloadableUpdater is a mock of DownloadableUpdater interface
+type MockDownloadableUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadableUpdaterMockRecorder
+}
+
+// MockDownloadableUpdaterMockRecorder is the mock recorder for MockDownloadableUpdater
+type MockDownloadableUpdaterMockRecorder struct {
+	mock *MockDownloadableUpdater
+}
+
+// NewMockDownloadableUpdater creates a new mock instance
+func NewMockDownloadableUpdater(ctrl *gomock.Controller) *MockDownloadableUpdater {
+	mock := &MockDownloadableUpdater{ctrl: ctrl}
+	mock.recorder = &MockDownloadableUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDownloadableUpdater) EXPECT() *MockDownloadableUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method
+func (m *MockDownloadableUpdater) Update(ctx context.Context, downloadable *minicommerce.Downloadable) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, downloadable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockDownloadableUpdaterMockRecorder) Update(ctx, downloadable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDownloadableUpdater)(nil).Update), ctx, downloadable)
+}
+
 // MockDownloadableDeleter is a mock of DownloadableDeleter interface
 type MockDownloadableDeleter struct {
 	ctrl     *gomock.Controller
@@ -138,6 +234,21 @@ func (mr *MockDownloadableDeleterMockRecorder) Delete(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDownloadableDeleter)(nil).Delete), ctx, id)
 }
 
+// DeleteReleasingFile mocks base method
+func (m *MockDownloadableDeleter) DeleteReleasingFile(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReleasingFile", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReleasingFile indicates an expected call of DeleteReleasingFile
+func (mr *MockDownloadableDeleterMockRecorder) DeleteReleasingFile(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReleasingFile", reflect.TypeOf((*MockDownloadableDeleter)(nil).DeleteReleasingFile), ctx, id)
+}
+
 // MockDownloadableRepository is a mock of DownloadableRepository interface
 type MockDownloadableRepository struct {
 	ctrl     *gomock.Controller
@@ -176,6 +287,21 @@ func (mr *MockDownloadableRepositoryMockRecorder) Get(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDownloadableRepository)(nil).Get), ctx, id)
 }
 
+// GetByHash mocks base method
+func (m *MockDownloadableRepository) GetByHash(ctx context.Context, hash string) (*minicommerce.Downloadable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(*minicommerce.Downloadable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash
+func (mr *MockDownloadableRepositoryMockRecorder) GetByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockDownloadableRepository)(nil).GetByHash), ctx, hash)
+}
+
 // GetAll mocks base method
 func (m *MockDownloadableRepository) GetAll(ctx context.Context) ([]minicommerce.Downloadable, error) {
 	m.ctrl.T.Helper()
@@ -191,6 +317,36 @@ func (mr *MockDownloadableRepositoryMockRecorder) GetAll(ctx interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockDownloadableRepository)(nil).GetAll), ctx)
 }
 
+// List mocks base method
+func (m *MockDownloadableRepository) List(ctx context.Context, opts minicommerce.ListOptions) (*minicommerce.DownloadablePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, opts)
+	ret0, _ := ret[0].(*minicommerce.DownloadablePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockDownloadableRepositoryMockRecorder) List(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDownloadableRepository)(nil).List), ctx, opts)
+}
+
+// LocationReferenced mocks base method
+func (m *MockDownloadableRepository) LocationReferenced(ctx context.Context, location string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocationReferenced", ctx, location)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LocationReferenced indicates an expected call of LocationReferenced
+func (mr *MockDownloadableRepositoryMockRecorder) LocationReferenced(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocationReferenced", reflect.TypeOf((*MockDownloadableRepository)(nil).LocationReferenced), ctx, location)
+}
+
 // Create mocks base method
 func (m *MockDownloadableRepository) Create(ctx context.Context, downloadable *minicommerce.Downloadable) error {
 	m.ctrl.T.Helper()
@@ -205,6 +361,34 @@ func (mr *MockDownloadableRepositoryMockRecorder) Create(ctx, downloadable inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDownloadableRepository)(nil).Create), ctx, downloadable)
 }
 
+// CreateSharingFile mocks base method
+func (m *MockDownloadableRepository) CreateSharingFile(ctx context.Context, downloadable *minicommerce.Downloadable) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSharingFile", ctx, downloadable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSharingFile indicates an expected call of CreateSharingFile
+func (mr *MockDownloadableRepositoryMockRecorder) CreateSharingFile(ctx, downloadable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSharingFile", reflect.TypeOf((*MockDownloadableRepository)(nil).CreateSharingFile), ctx, downloadable)
+}
+
+// Update mocks base method
+func (m *MockDownloadableRepository) Update(ctx context.Context, downloadable *minicommerce.Downloadable) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, downloadable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockDownloadableRepositoryMockRecorder) Update(ctx, downloadable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDownloadableRepository)(nil).Update), ctx, downloadable)
+}
+
 // Delete mocks base method
 func (m *MockDownloadableRepository) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -218,3 +402,56 @@ func (mr *MockDownloadableRepositoryMockRecorder) Delete(ctx, id interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDownloadableRepository)(nil).Delete), ctx, id)
 }
+
+// DeleteReleasingFile mocks base method
+func (m *MockDownloadableRepository) DeleteReleasingFile(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReleasingFile", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReleasingFile indicates an expected call of DeleteReleasingFile
+func (mr *MockDownloadableRepositoryMockRecorder) DeleteReleasingFile(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReleasingFile", reflect.TypeOf((*MockDownloadableRepository)(nil).DeleteReleasingFile), ctx, id)
+}
+
+// MockDownloadableReferenceChecker is a mock of DownloadableReferenceChecker interface
+type MockDownloadableReferenceChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadableReferenceCheckerMockRecorder
+}
+
+// MockDownloadableReferenceCheckerMockRecorder is the mock recorder for MockDownloadableReferenceChecker
+type MockDownloadableReferenceCheckerMockRecorder struct {
+	mock *MockDownloadableReferenceChecker
+}
+
+// NewMockDownloadableReferenceChecker creates a new mock instance
+func NewMockDownloadableReferenceChecker(ctrl *gomock.Controller) *MockDownloadableReferenceChecker {
+	mock := &MockDownloadableReferenceChecker{ctrl: ctrl}
+	mock.recorder = &MockDownloadableReferenceCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDownloadableReferenceChecker) EXPECT() *MockDownloadableReferenceCheckerMockRecorder {
+	return m.recorder
+}
+
+// DownloadableReferenced mocks base method
+func (m *MockDownloadableReferenceChecker) DownloadableReferenced(ctx context.Context, downloadableID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadableReferenced", ctx, downloadableID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadableReferenced indicates an expected call of DownloadableReferenced
+func (mr *MockDownloadableReferenceCheckerMockRecorder) DownloadableReferenced(ctx, downloadableID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadableReferenced", reflect.TypeOf((*MockDownloadableReferenceChecker)(nil).DownloadableReferenced), ctx, downloadableID)
+}
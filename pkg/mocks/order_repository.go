@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/eikc/minicommerce (interfaces: OrderRepository,CustomerOrderReader)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	minicommerce "github.com/eikc/minicommerce"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockOrderRepository is a mock of OrderRepository interface
+type MockOrderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderRepositoryMockRecorder
+}
+
+// MockOrderRepositoryMockRecorder is the mock recorder for MockOrderRepository
+type MockOrderRepositoryMockRecorder struct {
+	mock *MockOrderRepository
+}
+
+// NewMockOrderRepository creates a new mock instance
+func NewMockOrderRepository(ctrl *gomock.Controller) *MockOrderRepository {
+	mock := &MockOrderRepository{ctrl: ctrl}
+	mock.recorder = &MockOrderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockOrderRepository) Create(arg0 context.Context, arg1 *minicommerce.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockOrderRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepository)(nil).Create), arg0, arg1)
+}
+
+// Get mocks base method
+func (m *MockOrderRepository) Get(arg0 context.Context, arg1 string) (*minicommerce.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*minicommerce.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockOrderRepositoryMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderRepository)(nil).Get), arg0, arg1)
+}
+
+// GetAll mocks base method
+func (m *MockOrderRepository) GetAll(arg0 context.Context) ([]minicommerce.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]minicommerce.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockOrderRepositoryMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrderRepository)(nil).GetAll), arg0)
+}
+
+// Update mocks base method
+func (m *MockOrderRepository) Update(arg0 context.Context, arg1 *minicommerce.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockOrderRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrderRepository)(nil).Update), arg0, arg1)
+}
+
+// MockCustomerOrderReader is a mock of CustomerOrderReader interface
+type MockCustomerOrderReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerOrderReaderMockRecorder
+}
+
+// MockCustomerOrderReaderMockRecorder is the mock recorder for MockCustomerOrderReader
+type MockCustomerOrderReaderMockRecorder struct {
+	mock *MockCustomerOrderReader
+}
+
+// NewMockCustomerOrderReader creates a new mock instance
+func NewMockCustomerOrderReader(ctrl *gomock.Controller) *MockCustomerOrderReader {
+	mock := &MockCustomerOrderReader{ctrl: ctrl}
+	mock.recorder = &MockCustomerOrderReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCustomerOrderReader) EXPECT() *MockCustomerOrderReaderMockRecorder {
+	return m.recorder
+}
+
+// GetByCustomerEmail mocks base method
+func (m *MockCustomerOrderReader) GetByCustomerEmail(arg0 context.Context, arg1 string) ([]minicommerce.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCustomerEmail", arg0, arg1)
+	ret0, _ := ret[0].([]minicommerce.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCustomerEmail indicates an expected call of GetByCustomerEmail
+func (mr *MockCustomerOrderReaderMockRecorder) GetByCustomerEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCustomerEmail", reflect.TypeOf((*MockCustomerOrderReader)(nil).GetByCustomerEmail), arg0, arg1)
+}
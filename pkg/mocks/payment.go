@@ -0,0 +1,461 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: payment.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	minicommerce "github.com/eikc/minicommerce"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPaymentReader is a mock of PaymentReader interface
+type MockPaymentReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentReaderMockRecorder
+}
+
+// MockPaymentReaderMockRecorder is the mock recorder for MockPaymentReader
+type MockPaymentReaderMockRecorder struct {
+	mock *MockPaymentReader
+}
+
+// NewMockPaymentReader creates a new mock instance
+func NewMockPaymentReader(ctrl *gomock.Controller) *MockPaymentReader {
+	mock := &MockPaymentReader{ctrl: ctrl}
+	mock.recorder = &MockPaymentReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentReader) EXPECT() *MockPaymentReaderMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockPaymentReader) Get(ctx context.Context, id string) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockPaymentReaderMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPaymentReader)(nil).Get), ctx, id)
+}
+
+// GetByExternalID mocks base method
+func (m *MockPaymentReader) GetByExternalID(ctx context.Context, externalID string) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByExternalID", ctx, externalID)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByExternalID indicates an expected call of GetByExternalID
+func (mr *MockPaymentReaderMockRecorder) GetByExternalID(ctx, externalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByExternalID", reflect.TypeOf((*MockPaymentReader)(nil).GetByExternalID), ctx, externalID)
+}
+
+// MockPaymentWriter is a mock of PaymentWriter interface
+type MockPaymentWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentWriterMockRecorder
+}
+
+// MockPaymentWriterMockRecorder is the mock recorder for MockPaymentWriter
+type MockPaymentWriterMockRecorder struct {
+	mock *MockPaymentWriter
+}
+
+// NewMockPaymentWriter creates a new mock instance
+func NewMockPaymentWriter(ctrl *gomock.Controller) *MockPaymentWriter {
+	mock := &MockPaymentWriter{ctrl: ctrl}
+	mock.recorder = &MockPaymentWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentWriter) EXPECT() *MockPaymentWriterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockPaymentWriter) Create(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPaymentWriterMockRecorder) Create(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentWriter)(nil).Create), ctx, payment)
+}
+
+// MockPaymentUpdater is a mock of PaymentUpdater interface
+type MockPaymentUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentUpdaterMockRecorder
+}
+
+// MockPaymentUpdaterMockRecorder is the mock recorder for MockPaymentUpdater
+type MockPaymentUpdaterMockRecorder struct {
+	mock *MockPaymentUpdater
+}
+
+// NewMockPaymentUpdater creates a new mock instance
+func NewMockPaymentUpdater(ctrl *gomock.Controller) *MockPaymentUpdater {
+	mock := &MockPaymentUpdater{ctrl: ctrl}
+	mock.recorder = &MockPaymentUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentUpdater) EXPECT() *MockPaymentUpdaterMockRecorder {
+	return m.recorder
+}
+
+// Update mocks base method
+func (m *MockPaymentUpdater) Update(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockPaymentUpdaterMockRecorder) Update(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPaymentUpdater)(nil).Update), ctx, payment)
+}
+
+// UpdateCharge mocks base method
+func (m *MockPaymentUpdater) UpdateCharge(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCharge", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCharge indicates an expected call of UpdateCharge
+func (mr *MockPaymentUpdaterMockRecorder) UpdateCharge(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCharge", reflect.TypeOf((*MockPaymentUpdater)(nil).UpdateCharge), ctx, payment)
+}
+
+// Modify mocks base method
+func (m *MockPaymentUpdater) Modify(ctx context.Context, externalID string, modify func(*minicommerce.Payment) bool) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Modify", ctx, externalID, modify)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockPaymentUpdaterMockRecorder) Modify(ctx, externalID, modify interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockPaymentUpdater)(nil).Modify), ctx, externalID, modify)
+}
+
+// MockPaymentRepository is a mock of PaymentRepository interface
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockPaymentRepository) Get(ctx context.Context, id string) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockPaymentRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPaymentRepository)(nil).Get), ctx, id)
+}
+
+// GetByExternalID mocks base method
+func (m *MockPaymentRepository) GetByExternalID(ctx context.Context, externalID string) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByExternalID", ctx, externalID)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByExternalID indicates an expected call of GetByExternalID
+func (mr *MockPaymentRepositoryMockRecorder) GetByExternalID(ctx, externalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByExternalID", reflect.TypeOf((*MockPaymentRepository)(nil).GetByExternalID), ctx, externalID)
+}
+
+// Create mocks base method
+func (m *MockPaymentRepository) Create(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPaymentRepositoryMockRecorder) Create(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentRepository)(nil).Create), ctx, payment)
+}
+
+// Update mocks base method
+func (m *MockPaymentRepository) Update(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockPaymentRepositoryMockRecorder) Update(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPaymentRepository)(nil).Update), ctx, payment)
+}
+
+// UpdateCharge mocks base method
+func (m *MockPaymentRepository) UpdateCharge(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCharge", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCharge indicates an expected call of UpdateCharge
+func (mr *MockPaymentRepositoryMockRecorder) UpdateCharge(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCharge", reflect.TypeOf((*MockPaymentRepository)(nil).UpdateCharge), ctx, payment)
+}
+
+// Modify mocks base method
+func (m *MockPaymentRepository) Modify(ctx context.Context, externalID string, modify func(*minicommerce.Payment) bool) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Modify", ctx, externalID, modify)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockPaymentRepositoryMockRecorder) Modify(ctx, externalID, modify interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockPaymentRepository)(nil).Modify), ctx, externalID, modify)
+}
+
+// MockPaymentProvider is a mock of PaymentProvider interface
+type MockPaymentProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentProviderMockRecorder
+}
+
+// MockPaymentProviderMockRecorder is the mock recorder for MockPaymentProvider
+type MockPaymentProviderMockRecorder struct {
+	mock *MockPaymentProvider
+}
+
+// NewMockPaymentProvider creates a new mock instance
+func NewMockPaymentProvider(ctrl *gomock.Controller) *MockPaymentProvider {
+	mock := &MockPaymentProvider{ctrl: ctrl}
+	mock.recorder = &MockPaymentProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentProvider) EXPECT() *MockPaymentProviderMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method
+func (m *MockPaymentProvider) Authorize(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockPaymentProviderMockRecorder) Authorize(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockPaymentProvider)(nil).Authorize), ctx, payment)
+}
+
+// Void mocks base method
+func (m *MockPaymentProvider) Void(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void
+func (mr *MockPaymentProviderMockRecorder) Void(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockPaymentProvider)(nil).Void), ctx, payment)
+}
+
+// Capture mocks base method
+func (m *MockPaymentProvider) Capture(ctx context.Context, payment *minicommerce.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture
+func (mr *MockPaymentProviderMockRecorder) Capture(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockPaymentProvider)(nil).Capture), ctx, payment)
+}
+
+// Refund mocks base method
+func (m *MockPaymentProvider) Refund(ctx context.Context, payment *minicommerce.Payment, amount int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, payment, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockPaymentProviderMockRecorder) Refund(ctx, payment, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockPaymentProvider)(nil).Refund), ctx, payment, amount)
+}
+
+// Lookup mocks base method
+func (m *MockPaymentProvider) Lookup(ctx context.Context, externalID string) (*minicommerce.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", ctx, externalID)
+	ret0, _ := ret[0].(*minicommerce.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup
+func (mr *MockPaymentProviderMockRecorder) Lookup(ctx, externalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockPaymentProvider)(nil).Lookup), ctx, externalID)
+}
+
+// MockOrderRefunder is a mock of OrderRefunder interface
+type MockOrderRefunder struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderRefunderMockRecorder
+}
+
+// MockOrderRefunderMockRecorder is the mock recorder for MockOrderRefunder
+type MockOrderRefunderMockRecorder struct {
+	mock *MockOrderRefunder
+}
+
+// NewMockOrderRefunder creates a new mock instance
+func NewMockOrderRefunder(ctrl *gomock.Controller) *MockOrderRefunder {
+	mock := &MockOrderRefunder{ctrl: ctrl}
+	mock.recorder = &MockOrderRefunderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrderRefunder) EXPECT() *MockOrderRefunderMockRecorder {
+	return m.recorder
+}
+
+// Refund mocks base method
+func (m *MockOrderRefunder) Refund(ctx context.Context, orderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockOrderRefunderMockRecorder) Refund(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockOrderRefunder)(nil).Refund), ctx, orderID)
+}
+
+// MockPaymentEventRepository is a mock of PaymentEventRepository interface
+type MockPaymentEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentEventRepositoryMockRecorder
+}
+
+// MockPaymentEventRepositoryMockRecorder is the mock recorder for MockPaymentEventRepository
+type MockPaymentEventRepositoryMockRecorder struct {
+	mock *MockPaymentEventRepository
+}
+
+// NewMockPaymentEventRepository creates a new mock instance
+func NewMockPaymentEventRepository(ctrl *gomock.Controller) *MockPaymentEventRepository {
+	mock := &MockPaymentEventRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentEventRepository) EXPECT() *MockPaymentEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockPaymentEventRepository) Get(ctx context.Context, id string) (*minicommerce.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*minicommerce.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockPaymentEventRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPaymentEventRepository)(nil).Get), ctx, id)
+}
+
+// Create mocks base method
+func (m *MockPaymentEventRepository) Create(ctx context.Context, event *minicommerce.PaymentEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPaymentEventRepositoryMockRecorder) Create(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentEventRepository)(nil).Create), ctx, event)
+}
@@ -1,7 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/eikc/minicommerce (interfaces: ProductRepository)
+// Source: github.com/eikc/minicommerce (interfaces: ProductRepository,ProductReferenceChecker)
 
-// Package mock_minicommerce is a generated GoMock package.
+// Package mocks is a generated GoMock package.
 package mocks
 
 import (
@@ -48,6 +48,20 @@ func (mr *MockProductRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProductRepository)(nil).Create), arg0, arg1)
 }
 
+// Delete mocks base method
+func (m *MockProductRepository) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockProductRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProductRepository)(nil).Delete), arg0, arg1)
+}
+
 // Get mocks base method
 func (m *MockProductRepository) Get(arg0 context.Context, arg1 string) (*minicommerce.Product, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +92,21 @@ func (mr *MockProductRepositoryMockRecorder) GetAll(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductRepository)(nil).GetAll), arg0)
 }
 
+// List mocks base method
+func (m *MockProductRepository) List(arg0 context.Context, arg1 minicommerce.ProductFilter, arg2 minicommerce.ListOptions) (*minicommerce.ProductPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*minicommerce.ProductPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockProductRepositoryMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProductRepository)(nil).List), arg0, arg1, arg2)
+}
+
 // Update mocks base method
 func (m *MockProductRepository) Update(arg0 context.Context, arg1 *minicommerce.Product) error {
 	m.ctrl.T.Helper()
@@ -91,3 +120,41 @@ func (mr *MockProductRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProductRepository)(nil).Update), arg0, arg1)
 }
+
+// MockProductReferenceChecker is a mock of ProductReferenceChecker interface
+type MockProductReferenceChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductReferenceCheckerMockRecorder
+}
+
+// MockProductReferenceCheckerMockRecorder is the mock recorder for MockProductReferenceChecker
+type MockProductReferenceCheckerMockRecorder struct {
+	mock *MockProductReferenceChecker
+}
+
+// NewMockProductReferenceChecker creates a new mock instance
+func NewMockProductReferenceChecker(ctrl *gomock.Controller) *MockProductReferenceChecker {
+	mock := &MockProductReferenceChecker{ctrl: ctrl}
+	mock.recorder = &MockProductReferenceCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProductReferenceChecker) EXPECT() *MockProductReferenceCheckerMockRecorder {
+	return m.recorder
+}
+
+// ProductReferenced mocks base method
+func (m *MockProductReferenceChecker) ProductReferenced(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductReferenced", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductReferenced indicates an expected call of ProductReferenced
+func (mr *MockProductReferenceCheckerMockRecorder) ProductReferenced(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductReferenced", reflect.TypeOf((*MockProductReferenceChecker)(nil).ProductReferenced), arg0, arg1)
+}
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	context "context"
+	minicommerce "github.com/eikc/minicommerce"
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
@@ -35,17 +36,17 @@ func (m *MockStorageWriter) EXPECT() *MockStorageWriterMockRecorder {
 }
 
 // Write mocks base method
-func (m *MockStorageWriter) Write(ctx context.Context, location string, r io.Reader) error {
+func (m *MockStorageWriter) Write(ctx context.Context, location string, r io.Reader, metadata *minicommerce.StorageMetadata) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", ctx, location, r)
+	ret := m.ctrl.Call(m, "Write", ctx, location, r, metadata)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write
-func (mr *MockStorageWriterMockRecorder) Write(ctx, location, r interface{}) *gomock.Call {
+func (mr *MockStorageWriterMockRecorder) Write(ctx, location, r, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStorageWriter)(nil).Write), ctx, location, r)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStorageWriter)(nil).Write), ctx, location, r, metadata)
 }
 
 // MockStorageReader is a mock of StorageReader interface
@@ -86,6 +87,157 @@ func (mr *MockStorageReaderMockRecorder) Read(ctx, location interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStorageReader)(nil).Read), ctx, location)
 }
 
+// MockStorageStater is a mock of StorageStater interface
+type MockStorageStater struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageStaterMockRecorder
+}
+
+// MockStorageStaterMockRecorder is the mock recorder for MockStorageStater
+type MockStorageStaterMockRecorder struct {
+	mock *MockStorageStater
+}
+
+// NewMockStorageStater creates a new mock instance
+func NewMockStorageStater(ctrl *gomock.Controller) *MockStorageStater {
+	mock := &MockStorageStater{ctrl: ctrl}
+	mock.recorder = &MockStorageStaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStorageStater) EXPECT() *MockStorageStaterMockRecorder {
+	return m.recorder
+}
+
+// Stat mocks base method
+func (m *MockStorageStater) Stat(ctx context.Context, location string) (*minicommerce.StorageAttributes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", ctx, location)
+	ret0, _ := ret[0].(*minicommerce.StorageAttributes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat
+func (mr *MockStorageStaterMockRecorder) Stat(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockStorageStater)(nil).Stat), ctx, location)
+}
+
+// MockStorageRangeReader is a mock of StorageRangeReader interface
+type MockStorageRangeReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageRangeReaderMockRecorder
+}
+
+// MockStorageRangeReaderMockRecorder is the mock recorder for MockStorageRangeReader
+type MockStorageRangeReaderMockRecorder struct {
+	mock *MockStorageRangeReader
+}
+
+// NewMockStorageRangeReader creates a new mock instance
+func NewMockStorageRangeReader(ctrl *gomock.Controller) *MockStorageRangeReader {
+	mock := &MockStorageRangeReader{ctrl: ctrl}
+	mock.recorder = &MockStorageRangeReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStorageRangeReader) EXPECT() *MockStorageRangeReaderMockRecorder {
+	return m.recorder
+}
+
+// ReadRange mocks base method
+func (m *MockStorageRangeReader) ReadRange(ctx context.Context, location string, offset, length int64) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRange", ctx, location, offset, length)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRange indicates an expected call of ReadRange
+func (mr *MockStorageRangeReaderMockRecorder) ReadRange(ctx, location, offset, length interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRange", reflect.TypeOf((*MockStorageRangeReader)(nil).ReadRange), ctx, location, offset, length)
+}
+
+// MockStorageLister is a mock of StorageLister interface
+type MockStorageLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageListerMockRecorder
+}
+
+// MockStorageListerMockRecorder is the mock recorder for MockStorageLister
+type MockStorageListerMockRecorder struct {
+	mock *MockStorageLister
+}
+
+// NewMockStorageLister creates a new mock instance
+func NewMockStorageLister(ctrl *gomock.Controller) *MockStorageLister {
+	mock := &MockStorageLister{ctrl: ctrl}
+	mock.recorder = &MockStorageListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStorageLister) EXPECT() *MockStorageListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method
+func (m *MockStorageLister) List(ctx context.Context, prefix string) ([]minicommerce.StorageObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, prefix)
+	ret0, _ := ret[0].([]minicommerce.StorageObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockStorageListerMockRecorder) List(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStorageLister)(nil).List), ctx, prefix)
+}
+
+// MockStorageCopier is a mock of StorageCopier interface
+type MockStorageCopier struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageCopierMockRecorder
+}
+
+// MockStorageCopierMockRecorder is the mock recorder for MockStorageCopier
+type MockStorageCopierMockRecorder struct {
+	mock *MockStorageCopier
+}
+
+// NewMockStorageCopier creates a new mock instance
+func NewMockStorageCopier(ctrl *gomock.Controller) *MockStorageCopier {
+	mock := &MockStorageCopier{ctrl: ctrl}
+	mock.recorder = &MockStorageCopierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStorageCopier) EXPECT() *MockStorageCopierMockRecorder {
+	return m.recorder
+}
+
+// Copy mocks base method
+func (m *MockStorageCopier) Copy(ctx context.Context, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy
+func (mr *MockStorageCopierMockRecorder) Copy(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockStorageCopier)(nil).Copy), ctx, from, to)
+}
+
 // MockStorageDeleter is a mock of StorageDeleter interface
 type MockStorageDeleter struct {
 	ctrl     *gomock.Controller
@@ -147,17 +299,17 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 }
 
 // Write mocks base method
-func (m *MockStorage) Write(ctx context.Context, location string, r io.Reader) error {
+func (m *MockStorage) Write(ctx context.Context, location string, r io.Reader, metadata *minicommerce.StorageMetadata) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", ctx, location, r)
+	ret := m.ctrl.Call(m, "Write", ctx, location, r, metadata)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write
-func (mr *MockStorageMockRecorder) Write(ctx, location, r interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) Write(ctx, location, r, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStorage)(nil).Write), ctx, location, r)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStorage)(nil).Write), ctx, location, r, metadata)
 }
 
 // Read mocks base method
@@ -175,6 +327,65 @@ func (mr *MockStorageMockRecorder) Read(ctx, location interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStorage)(nil).Read), ctx, location)
 }
 
+// ReadRange mocks base method
+func (m *MockStorage) ReadRange(ctx context.Context, location string, offset, length int64) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRange", ctx, location, offset, length)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRange indicates an expected call of ReadRange
+func (mr *MockStorageMockRecorder) ReadRange(ctx, location, offset, length interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRange", reflect.TypeOf((*MockStorage)(nil).ReadRange), ctx, location, offset, length)
+}
+
+// Stat mocks base method
+func (m *MockStorage) Stat(ctx context.Context, location string) (*minicommerce.StorageAttributes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", ctx, location)
+	ret0, _ := ret[0].(*minicommerce.StorageAttributes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat
+func (mr *MockStorageMockRecorder) Stat(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockStorage)(nil).Stat), ctx, location)
+}
+
+// List mocks base method
+func (m *MockStorage) List(ctx context.Context, prefix string) ([]minicommerce.StorageObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, prefix)
+	ret0, _ := ret[0].([]minicommerce.StorageObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockStorageMockRecorder) List(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStorage)(nil).List), ctx, prefix)
+}
+
+// Copy mocks base method
+func (m *MockStorage) Copy(ctx context.Context, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy
+func (mr *MockStorageMockRecorder) Copy(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockStorage)(nil).Copy), ctx, from, to)
+}
+
 // Delete mocks base method
 func (m *MockStorage) Delete(ctx context.Context, location string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,137 @@
+package pricing
+
+import (
+	"math"
+
+	"github.com/eikc/minicommerce"
+)
+
+// basisPoints is the denominator for rates, a rate of 2500 is 25%
+const basisPoints int64 = 10000
+
+// Rules are the shipping and tax rules the totals of an order are calculated with
+type Rules struct {
+	// ShippingFee is charged once for orders that contain shippable products
+	ShippingFee int64
+	// FreeShippingFrom waives the shipping fee when the discounted amount reaches it, zero disables free shipping
+	FreeShippingFrom int64
+	// TaxRate is the tax in basis points that is added on top of the net amount
+	TaxRate int64
+}
+
+// DefaultRules are the rules for a danish shop, 25% VAT and no shipping fee
+func DefaultRules() Rules {
+	return Rules{
+		TaxRate: 2500,
+	}
+}
+
+// Calculate derives Amount, Discount, Shipping, NetAmount, Taxes and Total of the order from the items in it.
+// The coupon is optional, when given either the AmountOff or the PercentOff of it is used as discount
+// on the items the coupon is eligible for.
+// All amounts are in the smallest unit of the currency and every division is rounded half up,
+// so the same order always ends up with the same totals
+func Calculate(order *minicommerce.Order, coupon *minicommerce.Coupon, rules Rules) {
+	var amount, eligible int64
+	shippable := false
+	for _, item := range order.Items {
+		amount += item.Price
+		if Eligible(item, coupon) {
+			eligible += item.Price
+		}
+		if item.Type == minicommerce.ProductTypeShippable {
+			shippable = true
+		}
+	}
+
+	order.Amount = amount
+	order.Discount = Discount(eligible, coupon)
+
+	order.Shipping = 0
+	discounted := order.Amount - order.Discount
+	if shippable && (rules.FreeShippingFrom == 0 || discounted < rules.FreeShippingFrom) {
+		order.Shipping = rules.ShippingFee
+	}
+
+	order.NetAmount = discounted + order.Shipping
+	order.Taxes = divRound(order.NetAmount*rules.TaxRate, basisPoints)
+	order.Total = order.NetAmount + order.Taxes
+}
+
+// Amount is the sum of the prices of the items
+func Amount(items []minicommerce.Product) int64 {
+	var amount int64
+	for _, item := range items {
+		amount += item.Price
+	}
+
+	return amount
+}
+
+// Eligible reports whether the coupon gives a discount on the item. A coupon restricted to
+// product IDs or product types only gives a discount on items matching all of its restrictions
+func Eligible(item minicommerce.Product, coupon *minicommerce.Coupon) bool {
+	if coupon == nil {
+		return false
+	}
+
+	if len(coupon.ProductIDs) > 0 && !containsID(coupon.ProductIDs, item.ID) {
+		return false
+	}
+
+	if len(coupon.ProductTypes) > 0 && !containsType(coupon.ProductTypes, item.Type) {
+		return false
+	}
+
+	return true
+}
+
+func containsID(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsType(types []minicommerce.ProductType, t minicommerce.ProductType) bool {
+	for _, i := range types {
+		if i == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Discount is the discount the coupon gives on the amount. AmountOff takes precedence over PercentOff,
+// a coupon never gives both, and the discount is never larger than the amount
+func Discount(amount int64, coupon *minicommerce.Coupon) int64 {
+	if coupon == nil {
+		return 0
+	}
+
+	var discount int64
+	switch {
+	case coupon.AmountOff > 0:
+		discount = coupon.AmountOff
+	case coupon.PercentOff > 0:
+		// PercentOff is a percentage between 0 and 100, it is converted to basis points
+		// before any calculation so floating point errors can't leak into the totals
+		rate := int64(math.Round(coupon.PercentOff * 100))
+		discount = divRound(amount*rate, basisPoints)
+	}
+
+	if discount > amount {
+		return amount
+	}
+
+	return discount
+}
+
+// divRound divides two non negative numbers and rounds half up
+func divRound(x, y int64) int64 {
+	return (x + y/2) / y
+}
@@ -0,0 +1,145 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/eikc/minicommerce"
+)
+
+func TestCalculate(t *testing.T) {
+	type totals struct {
+		Amount, Discount, Shipping, NetAmount, Taxes, Total int64
+	}
+
+	rules := Rules{
+		ShippingFee:      4900,
+		FreeShippingFrom: 50000,
+		TaxRate:          2500,
+	}
+
+	testCases := []struct {
+		desc     string
+		items    []minicommerce.Product
+		coupon   *minicommerce.Coupon
+		expected totals
+	}{
+		{
+			desc:     "An empty order has no totals",
+			expected: totals{},
+		},
+		{
+			desc: "Digital products are not charged shipping",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeDigital, Price: 10000},
+				{Type: minicommerce.ProductTypeLink, Price: 5000},
+			},
+			expected: totals{Amount: 15000, NetAmount: 15000, Taxes: 3750, Total: 18750},
+		},
+		{
+			desc: "Shippable products are charged shipping, which is taxed",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeShippable, Price: 10000},
+			},
+			expected: totals{Amount: 10000, Shipping: 4900, NetAmount: 14900, Taxes: 3725, Total: 18625},
+		},
+		{
+			desc: "Shipping is free from the free shipping amount",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeShippable, Price: 50000},
+			},
+			expected: totals{Amount: 50000, NetAmount: 50000, Taxes: 12500, Total: 62500},
+		},
+		{
+			desc: "Free shipping is based on the discounted amount",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeShippable, Price: 50000},
+			},
+			coupon:   &minicommerce.Coupon{AmountOff: 1000},
+			expected: totals{Amount: 50000, Discount: 1000, Shipping: 4900, NetAmount: 53900, Taxes: 13475, Total: 67375},
+		},
+		{
+			desc: "Percent off is rounded half up",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeDigital, Price: 999},
+			},
+			coupon:   &minicommerce.Coupon{PercentOff: 15},
+			expected: totals{Amount: 999, Discount: 150, NetAmount: 849, Taxes: 212, Total: 1061},
+		},
+		{
+			desc: "Amount off takes precedence over percent off",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeDigital, Price: 10000},
+			},
+			coupon:   &minicommerce.Coupon{AmountOff: 500, PercentOff: 50},
+			expected: totals{Amount: 10000, Discount: 500, NetAmount: 9500, Taxes: 2375, Total: 11875},
+		},
+		{
+			desc: "The discount is never larger than the amount",
+			items: []minicommerce.Product{
+				{Type: minicommerce.ProductTypeDigital, Price: 1000},
+			},
+			coupon:   &minicommerce.Coupon{AmountOff: 5000},
+			expected: totals{Amount: 1000, Discount: 1000},
+		},
+		{
+			desc: "A coupon restricted to products only discounts those products",
+			items: []minicommerce.Product{
+				{ID: "product-one", Type: minicommerce.ProductTypeDigital, Price: 10000},
+				{ID: "product-two", Type: minicommerce.ProductTypeDigital, Price: 5000},
+			},
+			coupon:   &minicommerce.Coupon{PercentOff: 10, ProductIDs: []string{"product-two"}},
+			expected: totals{Amount: 15000, Discount: 500, NetAmount: 14500, Taxes: 3625, Total: 18125},
+		},
+		{
+			desc: "A coupon restricted to a product type only discounts products of that type",
+			items: []minicommerce.Product{
+				{ID: "product-one", Type: minicommerce.ProductTypeDigital, Price: 10000},
+				{ID: "product-two", Type: minicommerce.ProductTypeShippable, Price: 60000},
+			},
+			coupon:   &minicommerce.Coupon{PercentOff: 50, ProductTypes: []minicommerce.ProductType{minicommerce.ProductTypeDigital}},
+			expected: totals{Amount: 70000, Discount: 5000, NetAmount: 65000, Taxes: 16250, Total: 81250},
+		},
+		{
+			desc: "Amount off is never larger than the amount of the eligible products",
+			items: []minicommerce.Product{
+				{ID: "product-one", Type: minicommerce.ProductTypeDigital, Price: 10000},
+				{ID: "product-two", Type: minicommerce.ProductTypeLink, Price: 300},
+			},
+			coupon:   &minicommerce.Coupon{AmountOff: 500, ProductTypes: []minicommerce.ProductType{minicommerce.ProductTypeLink}},
+			expected: totals{Amount: 10300, Discount: 300, NetAmount: 10000, Taxes: 2500, Total: 12500},
+		},
+		{
+			desc: "A coupon with both restrictions only discounts products matching both",
+			items: []minicommerce.Product{
+				{ID: "product-one", Type: minicommerce.ProductTypeDigital, Price: 10000},
+				{ID: "product-two", Type: minicommerce.ProductTypeLink, Price: 5000},
+			},
+			coupon: &minicommerce.Coupon{
+				PercentOff:   10,
+				ProductIDs:   []string{"product-one", "product-two"},
+				ProductTypes: []minicommerce.ProductType{minicommerce.ProductTypeLink},
+			},
+			expected: totals{Amount: 15000, Discount: 500, NetAmount: 14500, Taxes: 3625, Total: 18125},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			order := minicommerce.Order{Items: tC.items}
+
+			Calculate(&order, tC.coupon, rules)
+
+			result := totals{
+				Amount:    order.Amount,
+				Discount:  order.Discount,
+				Shipping:  order.Shipping,
+				NetAmount: order.NetAmount,
+				Taxes:     order.Taxes,
+				Total:     order.Total,
+			}
+
+			if result != tC.expected {
+				t.Errorf("expected %+v, got %+v", tC.expected, result)
+			}
+		})
+	}
+}
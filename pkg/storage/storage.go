@@ -4,56 +4,130 @@ import (
 	"context"
 	"io"
 
+	"github.com/eikc/minicommerce"
 	"gocloud.dev/blob"
 
 	// Enables the google cloud storage SDK
 	_ "gocloud.dev/blob/gcsblob"
+	// Enables file:// buckets in a local directory for development
+	_ "gocloud.dev/blob/fileblob"
+	// Enables mem:// buckets that only live as long as the process, for development and tests
+	_ "gocloud.dev/blob/memblob"
 )
 
-// BucketURL is the type for connecting to a common bucket
+// BucketURL is the type for connecting to a common bucket. The scheme selects the backend,
+// gs:// for google cloud storage, file:// for a local directory and mem:// for an in-memory bucket
 type BucketURL string
 
-// Storage is the interface to the blob storage
+// Storage is the interface to the blob storage. The bucket is opened once and shared by all requests,
+// it is safe for concurrent use
 type Storage struct {
-	BucketURL BucketURL
+	bucket *blob.Bucket
 }
 
-// NewStorage creates the storage struct with all the needed dependencies
-func NewStorage(bucketURL BucketURL) *Storage {
-	return &Storage{bucketURL}
+// NewStorage opens the bucket, the returned func closes it again and must be called on shutdown,
+// once nothing reads from or writes to the storage anymore
+func NewStorage(ctx context.Context, bucketURL BucketURL) (*Storage, func(), error) {
+	b, err := blob.OpenBucket(ctx, string(bucketURL))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Storage{b}, func() { b.Close() }, nil
 }
 
 // Read gets an object from the cloud storage
 func (s *Storage) Read(ctx context.Context, location string) (io.ReadCloser, error) {
-	b, err := blob.OpenBucket(ctx, string(s.BucketURL))
+	r, err := s.bucket.NewReader(ctx, location, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer b.Close()
 
-	r, err := b.NewReader(ctx, location, nil)
+	return r, nil
+}
+
+// Stat gets the attributes of an object in the cloud storage
+func (s *Storage) Stat(ctx context.Context, location string) (*minicommerce.StorageAttributes, error) {
+	attrs, err := s.bucket.Attributes(ctx, location)
 	if err != nil {
 		return nil, err
 	}
 
-	return r, nil
+	return &minicommerce.StorageAttributes{
+		Size:         attrs.Size,
+		ContentType:  attrs.ContentType,
+		CacheControl: attrs.CacheControl,
+		ModTime:      attrs.ModTime.Unix(),
+		MD5:          attrs.MD5,
+	}, nil
 }
 
-// Write adds an new object to the cloud storage
-func (s *Storage) Write(ctx context.Context, location string, r io.Reader) error {
-	b, err := blob.OpenBucket(ctx, string(s.BucketURL))
-	if err != nil {
+// List gets the objects in the cloud storage whose location starts with the prefix
+func (s *Storage) List(ctx context.Context, prefix string) ([]minicommerce.StorageObject, error) {
+	iter := s.bucket.List(&blob.ListOptions{Prefix: prefix})
+
+	var objects []minicommerce.StorageObject
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, minicommerce.StorageObject{
+			Location: obj.Key,
+			Size:     obj.Size,
+			ModTime:  obj.ModTime.Unix(),
+			MD5:      obj.MD5,
+		})
+	}
+}
+
+// Copy copies an object in the cloud storage to another location, the content never leaves the storage
+func (s *Storage) Copy(ctx context.Context, from, to string) error {
+	if err := s.bucket.Copy(ctx, to, from, nil); err != nil {
 		return err
 	}
-	defer b.Close()
 
-	w, err := b.NewWriter(ctx, location, nil)
+	return nil
+}
+
+// ReadRange gets length bytes of an object from the cloud storage starting at offset, a negative length reads to the end
+func (s *Storage) ReadRange(ctx context.Context, location string, offset, length int64) (io.ReadCloser, error) {
+	r, err := s.bucket.NewRangeReader(ctx, location, offset, length, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write adds an new object to the cloud storage. A write that fails half way is aborted,
+// so it never leaves a partial object behind
+func (s *Storage) Write(ctx context.Context, location string, r io.Reader, metadata *minicommerce.StorageMetadata) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var opts *blob.WriterOptions
+	if metadata != nil {
+		opts = &blob.WriterOptions{
+			ContentType:  metadata.ContentType,
+			CacheControl: metadata.CacheControl,
+		}
+	}
+
+	w, err := s.bucket.NewWriter(ctx, location, opts)
 	if err != nil {
 		return err
 	}
 
 	_, err = io.Copy(w, r)
 	if err != nil {
+		// the writer only discards the object when the context is canceled before it is closed
+		cancel()
+		w.Close()
 		return err
 	}
 
@@ -66,12 +140,7 @@ func (s *Storage) Write(ctx context.Context, location string, r io.Reader) error
 
 // Delete deletes an object from the cloud storage
 func (s *Storage) Delete(ctx context.Context, location string) error {
-	b, err := blob.OpenBucket(ctx, string(s.BucketURL))
-	if err != nil {
-		return err
-	}
-
-	if err := b.Delete(ctx, location); err != nil {
+	if err := s.bucket.Delete(ctx, location); err != nil {
 		return err
 	}
 
@@ -3,37 +3,260 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/eikc/minicommerce"
 )
 
+// TestStorage runs the same contract against every backend. The local ones run without a network,
+// google cloud storage only runs as an integration test
 func TestStorage(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Integration test skipped")
+	dir, err := ioutil.TempDir("", "minicommerce-storage")
+	if err != nil {
+		t.Fatal(err.Error())
 	}
+	defer os.RemoveAll(dir)
 
-	ctx := context.Background()
-	storage := NewStorage(BucketURL("gs://minicommerce_testing_123"))
+	backends := []struct {
+		desc        string
+		bucketURL   BucketURL
+		integration bool
+	}{
+		{desc: "memory", bucketURL: BucketURL("mem://")},
+		{desc: "filesystem", bucketURL: BucketURL("file://" + filepath.ToSlash(dir))},
+		{desc: "google cloud storage", bucketURL: BucketURL("gs://minicommerce_testing_123"), integration: true},
+	}
+	for _, b := range backends {
+		t.Run(b.desc, func(t *testing.T) {
+			if b.integration && testing.Short() {
+				t.Skip("Integration test skipped")
+			}
+
+			storage, closeBucket, err := NewStorage(context.Background(), b.bucketURL)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			defer closeBucket()
 
-	if err := storage.Write(ctx, "testing.txt", strings.NewReader("hello world")); err != nil {
-		t.Errorf(err.Error())
+			testContract(t, storage)
+		})
 	}
-	defer storage.Delete(ctx, "testing.txt")
+}
+
+func testContract(t *testing.T, storage *Storage) {
+	ctx := context.Background()
+
+	t.Run("A written object can be read back", func(t *testing.T) {
+		if err := storage.Write(ctx, "testing.txt", strings.NewReader("hello world"), nil); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer storage.Delete(ctx, "testing.txt")
+
+		if s := read(t, storage, "testing.txt"); s != "hello world" {
+			t.Errorf("expected hello world, got %s", s)
+		}
+	})
+
+	t.Run("The attributes of a written object can be read", func(t *testing.T) {
+		metadata := &minicommerce.StorageMetadata{ContentType: "text/csv", CacheControl: "private, max-age=60"}
+		if err := storage.Write(ctx, "testing-stat.txt", strings.NewReader("hello world"), metadata); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer storage.Delete(ctx, "testing-stat.txt")
+
+		attrs, err := storage.Stat(ctx, "testing-stat.txt")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if attrs.Size != 11 || attrs.ModTime == 0 {
+			t.Errorf("expected the size to be 11 and the modified time to be set, got %d and %d", attrs.Size, attrs.ModTime)
+		}
+
+		if attrs.ContentType != metadata.ContentType || attrs.CacheControl != metadata.CacheControl {
+			t.Errorf("expected the metadata %v, got %s and %s", metadata, attrs.ContentType, attrs.CacheControl)
+		}
+	})
+
+	t.Run("The content type of an object without metadata is detected", func(t *testing.T) {
+		if err := storage.Write(ctx, "testing-detect.txt", strings.NewReader("hello world"), nil); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer storage.Delete(ctx, "testing-detect.txt")
+
+		attrs, err := storage.Stat(ctx, "testing-detect.txt")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if !strings.HasPrefix(attrs.ContentType, "text/plain") {
+			t.Errorf("expected the content type to be text/plain, got %s", attrs.ContentType)
+		}
+	})
+
+	t.Run("The objects with a prefix can be listed", func(t *testing.T) {
+		locations := []string{"testing-list/b.txt", "testing-list/a.txt", "testing-other/c.txt"}
+		for _, location := range locations {
+			if err := storage.Write(ctx, location, strings.NewReader("hello world"), nil); err != nil {
+				t.Fatal(err.Error())
+			}
+			defer storage.Delete(ctx, location)
+		}
+
+		objects, err := storage.List(ctx, "testing-list/")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		var listed []string
+		for _, obj := range objects {
+			if obj.Size != 11 || obj.ModTime == 0 {
+				t.Errorf("expected %s to have a size of 11 and a modified time, got %d and %d", obj.Location, obj.Size, obj.ModTime)
+			}
+			listed = append(listed, obj.Location)
+		}
+
+		expected := []string{"testing-list/a.txt", "testing-list/b.txt"}
+		if !reflect.DeepEqual(listed, expected) {
+			t.Errorf("expected %v to be listed, got %v", expected, listed)
+		}
+	})
+
+	t.Run("An object can be copied", func(t *testing.T) {
+		metadata := &minicommerce.StorageMetadata{ContentType: "text/csv"}
+		if err := storage.Write(ctx, "testing-copy.txt", strings.NewReader("hello world"), metadata); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer storage.Delete(ctx, "testing-copy.txt")
+
+		if err := storage.Copy(ctx, "testing-copy.txt", "testing-copied.txt"); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer storage.Delete(ctx, "testing-copied.txt")
 
-	r, err := storage.Read(ctx, "testing.txt")
+		if s := read(t, storage, "testing-copied.txt"); s != "hello world" {
+			t.Errorf("expected hello world, got %s", s)
+		}
+
+		attrs, err := storage.Stat(ctx, "testing-copied.txt")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if attrs.ContentType != metadata.ContentType {
+			t.Errorf("expected the copy to keep the content type %s, got %s", metadata.ContentType, attrs.ContentType)
+		}
+	})
+
+	t.Run("A range of a written object can be read", func(t *testing.T) {
+		if err := storage.Write(ctx, "testing-range.txt", strings.NewReader("hello world"), nil); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer storage.Delete(ctx, "testing-range.txt")
+
+		r, err := storage.ReadRange(ctx, "testing-range.txt", 6, -1)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		defer r.Close()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(r); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if s := buf.String(); s != "world" {
+			t.Errorf("expected the range to be world, got %s", s)
+		}
+	})
+
+	t.Run("A deleted object can't be read", func(t *testing.T) {
+		if err := storage.Write(ctx, "testing-delete.txt", strings.NewReader("hello world"), nil); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if err := storage.Delete(ctx, "testing-delete.txt"); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if _, err := storage.Read(ctx, "testing-delete.txt"); err == nil {
+			t.Errorf("expected an error reading a deleted object")
+		}
+	})
+
+	t.Run("A write that fails half way leaves nothing behind", func(t *testing.T) {
+		r := io.MultiReader(strings.NewReader("hello"), &failingReader{})
+		if err := storage.Write(ctx, "testing-failed.txt", r, nil); err == nil {
+			t.Fatal("expected the write to fail")
+		}
+
+		if _, err := storage.Stat(ctx, "testing-failed.txt"); err == nil {
+			storage.Delete(ctx, "testing-failed.txt")
+			t.Errorf("expected no object after a failed write")
+		}
+	})
+
+	t.Run("Objects can be written and read from many goroutines at once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				location := fmt.Sprintf("testing-concurrent-%d.txt", i)
+				if err := storage.Write(ctx, location, strings.NewReader(location), nil); err != nil {
+					t.Error(err.Error())
+					return
+				}
+				defer storage.Delete(ctx, location)
+
+				if s := read(t, storage, location); s != location {
+					t.Errorf("expected %s, got %s", location, s)
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	t.Run("A missing object can't be read", func(t *testing.T) {
+		if _, err := storage.Stat(ctx, "testing-missing.txt"); err == nil {
+			t.Errorf("expected an error reading the attributes of a missing object")
+		}
+
+		if _, err := storage.Read(ctx, "testing-missing.txt"); err == nil {
+			t.Errorf("expected an error reading a missing object")
+		}
+	})
+}
+
+// failingReader fails every read, like an upload that is cut off
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func read(t *testing.T, storage *Storage, location string) string {
+	r, err := storage.Read(context.Background(), location)
 	if err != nil {
-		t.Errorf(err.Error())
+		t.Error(err.Error())
+		return ""
 	}
 	defer r.Close()
 
 	buf := new(bytes.Buffer)
-	_, err = buf.ReadFrom(r)
-	if err != nil {
-		t.Errorf(err.Error())
+	if _, err := buf.ReadFrom(r); err != nil {
+		t.Error(err.Error())
 	}
 
-	s := buf.String()
-	if s != "hello world" {
-		t.Errorf("the reader did not contain the correct text string")
-	}
+	return buf.String()
 }
@@ -2,6 +2,7 @@ package minicommerce
 
 import (
 	"context"
+	"net/url"
 )
 
 // ProductType is the representation of the product type within miniCommerce
@@ -29,10 +30,41 @@ type Product struct {
 	Downloadable []Downloadable    `firestore:"downloadable" json:"downloadables"`
 }
 
+// Validate checks the product against the rules of its type. Linkable products must link to an http(s) URL,
+// digital products must have at least one downloadable, shippable products can't have any downloadables
+// and the price can't be negative. A product that breaks any of the rules returns a ValidationError
+func (p *Product) Validate() error {
+	errs := &ValidationError{}
+
+	if p.Price < 0 {
+		errs.add("price", "must not be negative")
+	}
+
+	switch p.Type {
+	case ProductTypeLink:
+		if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("url", "must be an absolute http or https URL")
+		}
+	case ProductTypeDigital:
+		if len(p.Downloadable) == 0 {
+			errs.add("downloadables", "must contain at least one downloadable")
+		}
+	case ProductTypeShippable:
+		if len(p.Downloadable) > 0 {
+			errs.add("downloadables", "must be empty for shippable products")
+		}
+	default:
+		errs.add("type", "must be one of digital, linkable or shippable")
+	}
+
+	return errs.errOrNil()
+}
+
 // ProductReader is the interface for reading products from a given datastore
 type ProductReader interface {
 	GetAll(ctx context.Context) ([]Product, error)
 	Get(ctx context.Context, id string) (*Product, error)
+	List(ctx context.Context, filter ProductFilter, opts ListOptions) (*ProductPage, error)
 }
 
 // ProductWriter is the interface for creating a product in a given datastore
@@ -45,9 +77,20 @@ type ProductUpdater interface {
 	Update(ctx context.Context, product *Product) error
 }
 
+// ProductDeleter is the interface for deleting a product in a given datastore
+type ProductDeleter interface {
+	Delete(ctx context.Context, id string) error
+}
+
 // ProductRepository is the interface that combines all readers and writers for a product
 type ProductRepository interface {
 	ProductReader
 	ProductWriter
 	ProductUpdater
+	ProductDeleter
+}
+
+// ProductReferenceChecker checks whether a product is referenced by anything that would break if it was deleted
+type ProductReferenceChecker interface {
+	ProductReferenced(ctx context.Context, productID string) (bool, error)
 }
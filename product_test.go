@@ -0,0 +1,77 @@
+package minicommerce
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProductValidate(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		product Product
+		fields  []string
+	}{
+		{
+			desc:    "A linkable product with an https URL is valid",
+			product: Product{Type: ProductTypeLink, URL: "https://example.com/course"},
+		},
+		{
+			desc:    "A linkable product without a valid URL is invalid",
+			product: Product{Type: ProductTypeLink, URL: "example.com/course"},
+			fields:  []string{"url"},
+		},
+		{
+			desc:    "A digital product with a downloadable is valid",
+			product: Product{Type: ProductTypeDigital, Downloadable: []Downloadable{{ID: "file"}}},
+		},
+		{
+			desc:    "A digital product without downloadables is invalid",
+			product: Product{Type: ProductTypeDigital},
+			fields:  []string{"downloadables"},
+		},
+		{
+			desc:    "A shippable product without downloadables is valid",
+			product: Product{Type: ProductTypeShippable, Price: 10000},
+		},
+		{
+			desc:    "A shippable product with downloadables is invalid",
+			product: Product{Type: ProductTypeShippable, Downloadable: []Downloadable{{ID: "file"}}},
+			fields:  []string{"downloadables"},
+		},
+		{
+			desc:    "An unknown product type is invalid",
+			product: Product{Type: "physical"},
+			fields:  []string{"type"},
+		},
+		{
+			desc:    "Every invalid field is reported",
+			product: Product{Type: ProductTypeDigital, Price: -1},
+			fields:  []string{"price", "downloadables"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.product.Validate()
+			if tC.fields == nil {
+				if err != nil {
+					t.Errorf("expected the product to be valid, got %v", err)
+				}
+				return
+			}
+
+			validationErr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("expected a ValidationError, got %v", err)
+			}
+
+			var fields []string
+			for _, f := range validationErr.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			if !reflect.DeepEqual(fields, tC.fields) {
+				t.Errorf("expected the fields %v to be invalid, got %v", tC.fields, fields)
+			}
+		})
+	}
+}
@@ -5,9 +5,16 @@ import (
 	"io"
 )
 
-// StorageWriter ..
+// StorageMetadata is stored with an object and sent along when it is served. An empty ContentType
+// is detected from the content
+type StorageMetadata struct {
+	ContentType  string
+	CacheControl string
+}
+
+// StorageWriter writes an object, the metadata can be nil
 type StorageWriter interface {
-	Write(ctx context.Context, location string, r io.Reader) error
+	Write(ctx context.Context, location string, r io.Reader, metadata *StorageMetadata) error
 }
 
 // StorageReader ...
@@ -15,6 +22,44 @@ type StorageReader interface {
 	Read(ctx context.Context, location string) (io.ReadCloser, error)
 }
 
+// StorageAttributes describes a stored object. ModTime is a unix time and MD5 is empty when the backend doesn't provide it
+type StorageAttributes struct {
+	Size         int64
+	ContentType  string
+	CacheControl string
+	ModTime      int64
+	MD5          []byte
+}
+
+// StorageObject is a stored object as it is listed. ModTime is a unix time and MD5 is empty when the backend
+// doesn't provide it
+type StorageObject struct {
+	Location string
+	Size     int64
+	ModTime  int64
+	MD5      []byte
+}
+
+// StorageStater reads the attributes of a stored object
+type StorageStater interface {
+	Stat(ctx context.Context, location string) (*StorageAttributes, error)
+}
+
+// StorageRangeReader reads length bytes of a stored object starting at offset, a negative length reads to the end
+type StorageRangeReader interface {
+	ReadRange(ctx context.Context, location string, offset, length int64) (io.ReadCloser, error)
+}
+
+// StorageLister lists the objects whose location starts with the prefix, an empty prefix lists every object
+type StorageLister interface {
+	List(ctx context.Context, prefix string) ([]StorageObject, error)
+}
+
+// StorageCopier copies an object inside the storage, without downloading and uploading it again
+type StorageCopier interface {
+	Copy(ctx context.Context, from, to string) error
+}
+
 // StorageDeleter ..
 type StorageDeleter interface {
 	Delete(ctx context.Context, location string) error
@@ -24,5 +69,9 @@ type StorageDeleter interface {
 type Storage interface {
 	StorageWriter
 	StorageReader
+	StorageRangeReader
+	StorageStater
+	StorageLister
+	StorageCopier
 	StorageDeleter
 }
@@ -0,0 +1,40 @@
+package minicommerce
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes why the value of a single field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a model is invalid, it holds an error for every invalid field
+type ValidationError struct {
+	Fields []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	var fields []string
+	for _, f := range e.Fields {
+		fields = append(fields, fmt.Sprintf("%s %s", f.Field, f.Message))
+	}
+
+	return fmt.Sprintf("The validation failed: %s", strings.Join(fields, ", "))
+}
+
+// add records that the field is invalid
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// errOrNil returns the ValidationError if any field is invalid, and nil otherwise
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}